@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+var accessLogFormats = map[string]func(*accessRecord) string{
+	"combined": (*accessRecord).combined,
+	"json":     (*accessRecord).json,
+	"logfmt":   (*accessRecord).logfmt,
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *responseRecorder) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type accessRecord struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	RemoteIP  string    `json:"remote_ip"`
+	User      string    `json:"user"`
+	Method    string    `json:"method"`
+	Host      string    `json:"host"`
+	URI       string    `json:"uri"`
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Bytes     int64     `json:"bytes"`
+	LatencyMS float64   `json:"latency_ms"`
+	Referer   string    `json:"referer"`
+	UserAgent string    `json:"user_agent"`
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// combined follows the Apache combined log format, followed by the request
+// ID and the latency in milliseconds.
+func (record *accessRecord) combined() string {
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %d %q %q %s %.3f",
+		record.RemoteIP, orDash(record.User), record.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		record.Method, record.URI, record.Proto, record.Status, record.Bytes,
+		orDash(record.Referer), orDash(record.UserAgent), record.RequestID, record.LatencyMS)
+}
+
+func (record *accessRecord) json() string {
+	line, _ := json.Marshal(record)
+	return string(line)
+}
+
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\\\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func (record *accessRecord) logfmt() string {
+	return fmt.Sprintf("time=%s request_id=%s remote_ip=%s user=%s method=%s host=%s uri=%s proto=%s status=%d bytes=%d latency_ms=%.3f referer=%s user_agent=%s",
+		record.Time.Format(time.RFC3339Nano), logfmtValue(record.RequestID), logfmtValue(record.RemoteIP),
+		logfmtValue(record.User), logfmtValue(record.Method), logfmtValue(record.Host), logfmtValue(record.URI),
+		logfmtValue(record.Proto), record.Status, record.Bytes, record.LatencyMS,
+		logfmtValue(record.Referer), logfmtValue(record.UserAgent))
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withAccessLog writes a record for each request to output in the given
+// format. The request ID is taken from the X-Request-Id request header when
+// present, and is sent back in the X-Request-Id response header.
+func withAccessLog(handler http.Handler, format string, output io.Writer) (http.Handler, error) {
+	if format == "" || format == "none" {
+		return handler, nil
+	}
+	fmtRecord, ok := accessLogFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown access log format %q", format)
+	}
+
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		handler.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		record := accessRecord{
+			Time:      start,
+			RequestID: requestID,
+			RemoteIP:  r.RemoteAddr,
+			Method:    r.Method,
+			Host:      r.Host,
+			URI:       r.RequestURI,
+			Proto:     r.Proto,
+			Status:    recorder.status,
+			Bytes:     recorder.bytes,
+			LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+		}
+		if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			record.RemoteIP = ip
+		}
+		if user, _, ok := r.BasicAuth(); ok {
+			record.User = user
+		}
+
+		line := fmtRecord(&record)
+		mu.Lock()
+		defer mu.Unlock()
+		io.WriteString(output, line+"\n")
+	}), nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestWithAccessLog(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	})
+	createRequest := func(requestID string) *http.Request {
+		r, err := http.NewRequest("GET", "/status/418?a=1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RequestURI = "/status/418?a=1"
+		r.RemoteAddr = "192.168.1.100:10086"
+		r.Header.Set("User-Agent", "curl/7.54.0")
+		r.SetBasicAuth("user", "passwd")
+		if requestID != "" {
+			r.Header.Set(requestIDHeader, requestID)
+		}
+		return r
+	}
+	tests := []struct {
+		name      string
+		format    string
+		requestID string
+		result    *regexp.Regexp
+	}{
+		{
+			"TestWithAccessLogCombined", "combined", "abc",
+			regexp.MustCompile(`^192\.168\.1\.100 - user \[[^\]]+\] "GET /status/418\?a=1 HTTP/1\.1" 418 15 "-" "curl/7\.54\.0" abc \d+\.\d{3}\n$`),
+		},
+		{
+			"TestWithAccessLogLogfmt", "logfmt", "",
+			regexp.MustCompile(`^time=\S+ request_id=[0-9a-f]{32} remote_ip=192\.168\.1\.100 user=user method=GET host="" uri="/status/418\?a=1" proto=HTTP/1\.1 status=418 bytes=15 latency_ms=\d+\.\d{3} referer="" user_agent=curl/7\.54\.0\n$`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			logged, err := withAccessLog(handler, tt.format, &output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			logged.ServeHTTP(w, createRequest(tt.requestID))
+			if !tt.result.MatchString(output.String()) {
+				t.Errorf("wrong access log record: got %q want match of %v", output.String(), tt.result)
+			}
+			if tt.requestID != "" && w.Header().Get(requestIDHeader) != tt.requestID {
+				t.Errorf("wrong request id header: got %v want %v", w.Header().Get(requestIDHeader), tt.requestID)
+			}
+		})
+	}
+
+	t.Run("TestWithAccessLogJSON", func(t *testing.T) {
+		var output bytes.Buffer
+		logged, _ := withAccessLog(handler, "json", &output)
+		w := httptest.NewRecorder()
+		logged.ServeHTTP(w, createRequest(""))
+		var record accessRecord
+		if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record.Status != http.StatusTeapot || record.Bytes != 15 || record.RemoteIP != "192.168.1.100" || record.User != "user" {
+			t.Errorf("wrong access log record: got %+v", record)
+		}
+		if record.RequestID == "" || record.RequestID != w.Header().Get(requestIDHeader) {
+			t.Errorf("wrong request id: got %v in log and %v in header", record.RequestID, w.Header().Get(requestIDHeader))
+		}
+	})
+
+	t.Run("TestWithAccessLogUnknownFormat", func(t *testing.T) {
+		if _, err := withAccessLog(handler, "xml", &bytes.Buffer{}); err == nil {
+			t.Error("withAccessLog should reject unknown formats")
+		}
+	})
+}
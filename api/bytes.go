@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseBytesRequest parses /bytes/:n and /stream-bytes/:n requests, returning
+// the number of bytes and their source. Without a seed query parameter the
+// bytes are random, with one they are the same on every request.
+func parseBytesRequest(w http.ResponseWriter, r *http.Request) (int, *rand.Rand, bool) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return 0, nil, false
+	}
+	paths := strings.Split(r.URL.Path, "/")
+	if len(paths) != 3 {
+		w.WriteHeader(http.StatusBadRequest)
+		return 0, nil, false
+	}
+	n, err := strconv.Atoi(paths[2])
+	if err != nil || n < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return 0, nil, false
+	}
+	if n > maxBytes {
+		n = maxBytes
+	}
+	seed := rand.Int63()
+	if s := r.URL.Query().Get("seed"); s != "" {
+		if seed, err = strconv.ParseInt(s, 10, 64); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return 0, nil, false
+		}
+	}
+	return n, rand.New(rand.NewSource(seed)), true
+}
+
+func BytesHandler(w http.ResponseWriter, r *http.Request) {
+	n, source, ok := parseBytesRequest(w, r)
+	if !ok {
+		return
+	}
+	b := make([]byte, n)
+	source.Read(b)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(n))
+	w.Write(b)
+}
+
+func StreamBytesHandler(w http.ResponseWriter, r *http.Request) {
+	n, source, ok := parseBytesRequest(w, r)
+	if !ok {
+		return
+	}
+	chunkSize := 10 * 1024
+	if s := r.URL.Query().Get("chunk_size"); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		chunkSize = size
+	}
+	if chunkSize > n && n > 0 {
+		chunkSize = n
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if streamHead(w, r) {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	b := make([]byte, chunkSize)
+	for n > 0 {
+		if n < len(b) {
+			b = b[:n]
+		}
+		source.Read(b)
+		if _, err := w.Write(b); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		n -= len(b)
+	}
+}
+
+// rangeETag returns the ETag of /range/:numbytes. Like those of /etag/:etag,
+// it comes from the path, quoted as If-Range expects.
+func rangeETag(n int) string {
+	return `"range` + strconv.Itoa(n) + `"`
+}
+
+func RangeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	paths := strings.Split(r.URL.Path, "/")
+	if len(paths) != 3 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	n, err := strconv.Atoi(paths[2])
+	if err != nil || n < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if n > maxBytes {
+		n = maxBytes
+	}
+
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + i%26)
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("ETag", rangeETag(n))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+}
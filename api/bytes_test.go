@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBytesHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string) args {
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code          int
+		length        int
+		contentLength string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestBytesHandler1", createTestCase("GET", "/bytes/16"), result{200, 16, "16"}},
+		{"TestBytesHandler2", createTestCase("GET", "/bytes/0"), result{200, 0, "0"}},
+		{"TestBytesHandler3", createTestCase("GET", "/bytes/100?seed=42"), result{200, 100, "100"}},
+		{"TestBytesHandler4", createTestCase("GET", "/bytes/-1"), result{400, 0, ""}},
+		{"TestBytesHandler5", createTestCase("GET", "/bytes/abc"), result{400, 0, ""}},
+		{"TestBytesHandler6", createTestCase("GET", "/bytes/1?seed=abc"), result{400, 0, ""}},
+		{"TestBytesHandler7", createTestCase("GET", "/bytes/1/2"), result{400, 0, ""}},
+		{"TestBytesHandler8", createTestCase("POST", "/bytes/1"), result{405, 0, ""}},
+		{"TestBytesHandler9", createTestCase("GET", "/bytes/20971520"), result{200, 10485760, "10485760"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			BytesHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if length := tt.args.w.Body.Len(); length != tt.result.length {
+				t.Errorf("handler returned wrong body length: got %v want %v",
+					length, tt.result.length)
+			}
+			if contentLength := tt.args.w.Header().Get("Content-Length"); contentLength != tt.result.contentLength {
+				t.Errorf("handler returned wrong Content-Length header: got %q want %q",
+					contentLength, tt.result.contentLength)
+			}
+		})
+	}
+}
+
+func TestStreamBytesHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string) args {
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code   int
+		length int
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestStreamBytesHandler1", createTestCase("GET", "/stream-bytes/100"), result{200, 100}},
+		{"TestStreamBytesHandler2", createTestCase("GET", "/stream-bytes/100?chunk_size=7&seed=42"), result{200, 100}},
+		{"TestStreamBytesHandler3", createTestCase("GET", "/stream-bytes/0"), result{200, 0}},
+		{"TestStreamBytesHandler4", createTestCase("GET", "/stream-bytes/10?chunk_size=0"), result{400, 0}},
+		{"TestStreamBytesHandler5", createTestCase("GET", "/stream-bytes/10?chunk_size=abc"), result{400, 0}},
+		{"TestStreamBytesHandler6", createTestCase("GET", "/stream-bytes/abc"), result{400, 0}},
+		{"TestStreamBytesHandler7", createTestCase("POST", "/stream-bytes/10"), result{405, 0}},
+		{"TestStreamBytesHandler8", createTestCase("GET", "/stream-bytes/10/x"), result{400, 0}},
+		{"TestStreamBytesHandler9", createTestCase("GET", "/stream-bytes/20971520"), result{200, 10485760}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			StreamBytesHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if length := tt.args.w.Body.Len(); length != tt.result.length {
+				t.Errorf("handler returned wrong body length: got %v want %v",
+					length, tt.result.length)
+			}
+			if tt.args.w.Header().Get("Content-Length") != "" {
+				t.Errorf("handler returned a Content-Length header: %v",
+					tt.args.w.Header().Get("Content-Length"))
+			}
+		})
+	}
+}
+
+func TestBytesHandlerSeed(t *testing.T) {
+	get := func(handler http.HandlerFunc, path string) []byte {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Body.Bytes()
+	}
+	seeded := get(BytesHandler, "/bytes/1000?seed=42")
+	if !bytes.Equal(seeded, get(BytesHandler, "/bytes/1000?seed=42")) {
+		t.Error("handler returned different bytes for the same seed")
+	}
+	if bytes.Equal(seeded, get(BytesHandler, "/bytes/1000?seed=43")) {
+		t.Error("handler returned the same bytes for different seeds")
+	}
+	if bytes.Equal(get(BytesHandler, "/bytes/1000"), get(BytesHandler, "/bytes/1000")) {
+		t.Error("handler returned the same bytes without a seed")
+	}
+	for _, path := range []string{"/stream-bytes/1000?seed=42", "/stream-bytes/1000?seed=42&chunk_size=3", "/stream-bytes/1000?seed=42&chunk_size=5000"} {
+		if !bytes.Equal(seeded, get(StreamBytesHandler, path)) {
+			t.Errorf("%v returned different bytes than /bytes/1000?seed=42", path)
+		}
+	}
+}
+
+func TestRangeHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string, headers map[string]string) args {
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	etag := `"range26"`
+	type result struct {
+		code         int
+		body         string
+		contentRange string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestRangeHandler1", createTestCase("GET", "/range/26", nil), result{200, "abcdefghijklmnopqrstuvwxyz", ""}},
+		{"TestRangeHandler2", createTestCase("GET", "/range/30", nil), result{200, "abcdefghijklmnopqrstuvwxyzabcd", ""}},
+		{"TestRangeHandler3", createTestCase("GET", "/range/26", map[string]string{"Range": "bytes=2-4"}), result{206, "cde", "bytes 2-4/26"}},
+		{"TestRangeHandler4", createTestCase("GET", "/range/26", map[string]string{"Range": "bytes=24-"}), result{206, "yz", "bytes 24-25/26"}},
+		{"TestRangeHandler5", createTestCase("GET", "/range/26", map[string]string{"Range": "bytes=-3"}), result{206, "xyz", "bytes 23-25/26"}},
+		{"TestRangeHandler6", createTestCase("GET", "/range/26", map[string]string{"Range": "bytes=30-40"}), result{416, "", "bytes */26"}},
+		{"TestRangeHandler7", createTestCase("GET", "/range/26", map[string]string{"Range": "bytes=0-1", "If-Range": etag}), result{206, "ab", "bytes 0-1/26"}},
+		{"TestRangeHandler8", createTestCase("GET", "/range/26", map[string]string{"Range": "bytes=0-1", "If-Range": `"stale"`}), result{200, "abcdefghijklmnopqrstuvwxyz", ""}},
+		{"TestRangeHandler9", createTestCase("GET", "/range/-1", nil), result{400, "", ""}},
+		{"TestRangeHandler10", createTestCase("GET", "/range/1/2", nil), result{400, "", ""}},
+		{"TestRangeHandler11", createTestCase("POST", "/range/1", nil), result{405, "", ""}},
+		{"TestRangeHandler12", createTestCase("GET", "/range/20971520", map[string]string{"Range": "bytes=-3"}), result{206, "jkl", "bytes 10485757-10485759/10485760"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RangeHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if tt.result.code == http.StatusRequestedRangeNotSatisfiable {
+				tt.args.w.Body.Reset()
+			}
+			if body := tt.args.w.Body.String(); body != tt.result.body {
+				t.Errorf("handler returned wrong body: got %q want %q",
+					body, tt.result.body)
+			}
+			if contentRange := tt.args.w.Header().Get("Content-Range"); contentRange != tt.result.contentRange {
+				t.Errorf("handler returned wrong Content-Range header: got %q want %q",
+					contentRange, tt.result.contentRange)
+			}
+			if tt.result.code < 400 && tt.args.w.Header().Get("ETag") != etag && tt.args.r.URL.Path == "/range/26" {
+				t.Errorf("handler returned wrong ETag header: got %v want %v",
+					tt.args.w.Header().Get("ETag"), etag)
+			}
+			if tt.result.code < 400 && tt.args.w.Header().Get("Accept-Ranges") != "bytes" {
+				t.Errorf("handler returned wrong Accept-Ranges header: got %q want bytes",
+					tt.args.w.Header().Get("Accept-Ranges"))
+			}
+		})
+	}
+
+	t.Run("TestRangeHandlerMultipart", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "/range/26", nil)
+		r.Header.Set("Range", "bytes=0-1,-2")
+		w := httptest.NewRecorder()
+		RangeHandler(w, r)
+		if w.Code != http.StatusPartialContent {
+			t.Fatalf("handler returned wrong status code: got %v want %v",
+				w.Code, http.StatusPartialContent)
+		}
+		mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		if err != nil || mediaType != "multipart/byteranges" {
+			t.Fatalf("handler returned wrong Content-Type header: %v", w.Header().Get("Content-Type"))
+		}
+		reader := multipart.NewReader(w.Body, params["boundary"])
+		want := []struct{ contentRange, body string }{{"bytes 0-1/26", "ab"}, {"bytes 24-25/26", "yz"}}
+		for _, want := range want {
+			part, err := reader.NextPart()
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(part)
+			if part.Header.Get("Content-Range") != want.contentRange || string(body) != want.body {
+				t.Errorf("handler returned wrong part: got %q %q want %q %q",
+					part.Header.Get("Content-Range"), body, want.contentRange, want.body)
+			}
+		}
+		if _, err := reader.NextPart(); err != io.EOF {
+			t.Errorf("handler returned extra parts: %v", err)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query parameters of /cookies/set and /cookies/delete which set cookie
+// attributes instead of cookies. Use /cookies/set/:name/:value to set a
+// cookie named like one of them.
+var cookieAttributes = map[string]bool{
+	"domain":      true,
+	"path":        true,
+	"expires":     true,
+	"max_age":     true,
+	"secure":      true,
+	"httponly":    true,
+	"samesite":    true,
+	"partitioned": true,
+}
+
+func CookiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	type JSON struct {
+		Cookies map[string]string `json:"cookies"`
+	}
+	cookies := make(map[string]string)
+	for _, cookie := range r.Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(JSON{Cookies: cookies})
+}
+
+func parseBool(query url.Values, key string) (bool, error) {
+	if _, ok := query[key]; !ok {
+		return false, nil
+	}
+	if query.Get(key) == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(query.Get(key))
+}
+
+// newCookieTemplate returns a cookie carrying the attributes given in query.
+func newCookieTemplate(query url.Values) (*http.Cookie, error) {
+	cookie := &http.Cookie{
+		Domain: query.Get("domain"),
+		Path:   query.Get("path"),
+	}
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+
+	if expires := query.Get("expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, expires); err != nil {
+				return nil, err
+			}
+		}
+		cookie.Expires = t
+	}
+	if maxAge := query.Get("max_age"); maxAge != "" {
+		n, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return nil, err
+		}
+		// http.Cookie omits Max-Age when it is 0, and sends Max-Age=0 when
+		// it is negative.
+		if n <= 0 {
+			n = -1
+		}
+		cookie.MaxAge = n
+	}
+
+	var err error
+	if cookie.Secure, err = parseBool(query, "secure"); err != nil {
+		return nil, err
+	}
+	if cookie.HttpOnly, err = parseBool(query, "httponly"); err != nil {
+		return nil, err
+	}
+	if cookie.Partitioned, err = parseBool(query, "partitioned"); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(query.Get("samesite")) {
+	case "":
+	case "lax":
+		cookie.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "none":
+		cookie.SameSite = http.SameSiteNoneMode
+	default:
+		return nil, errors.New("invalid SameSite value")
+	}
+	return cookie, nil
+}
+
+func setCookie(w http.ResponseWriter, template *http.Cookie, name, value string) bool {
+	cookie := *template
+	cookie.Name, cookie.Value = name, value
+	if err := cookie.Valid(); err != nil {
+		return false
+	}
+	w.Header().Add("Set-Cookie", cookie.String())
+	return true
+}
+
+func SetCookiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	template, err := newCookieTemplate(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cookies := make(map[string]string)
+	paths := strings.Split(r.URL.Path, "/")
+	switch len(paths) {
+	case 3:
+		for k := range query {
+			if !cookieAttributes[k] {
+				cookies[k] = query.Get(k)
+			}
+		}
+	case 5:
+		cookies[paths[3]] = paths[4]
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	for name, value := range cookies {
+		if !setCookie(w, template, name, value) {
+			w.Header().Del("Set-Cookie")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Location", "/cookies")
+	w.WriteHeader(http.StatusFound)
+}
+
+func DeleteCookiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	template, err := newCookieTemplate(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	template.MaxAge = -1
+	template.Expires = time.Unix(0, 0)
+
+	for k := range query {
+		if cookieAttributes[k] {
+			continue
+		}
+		if !setCookie(w, template, k, "") {
+			w.Header().Del("Set-Cookie")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Location", "/cookies")
+	w.WriteHeader(http.StatusFound)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCookiesHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(cookies ...*http.Cookie) args {
+		r, err := http.NewRequest("GET", "/cookies", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cookie := range cookies {
+			r.AddCookie(cookie)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result map[string]string
+	}{
+		{"TestCookiesHandler1", createTestCase(), map[string]string{}},
+		{"TestCookiesHandler2", createTestCase(&http.Cookie{Name: "a", Value: "1"}, &http.Cookie{Name: "b", Value: "2"}), map[string]string{"a": "1", "b": "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CookiesHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, http.StatusOK)
+			}
+			var body struct{ Cookies map[string]string }
+			json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+			if !reflect.DeepEqual(body.Cookies, tt.result) {
+				t.Errorf("handler returned wrong response json body: got %v want %v",
+					body.Cookies, tt.result)
+			}
+		})
+	}
+}
+
+func TestSetCookiesHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(path string) args {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code    int
+		cookies []string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestSetCookiesHandler1", createTestCase("/cookies/set?a=1&b=2"), result{302, []string{"a=1; Path=/", "b=2; Path=/"}}},
+		{"TestSetCookiesHandler2", createTestCase("/cookies/set/path/1"), result{302, []string{"path=1; Path=/"}}},
+		{
+			"TestSetCookiesHandler3",
+			createTestCase("/cookies/set?a=1&domain=example.com&path=/cookies&max_age=60&secure&httponly=true&samesite=strict&partitioned"),
+			result{302, []string{"a=1; Path=/cookies; Domain=example.com; Max-Age=60; HttpOnly; Secure; SameSite=Strict; Partitioned"}},
+		},
+		{
+			"TestSetCookiesHandler4",
+			createTestCase("/cookies/set?a=1&expires=Wed,%2021%20Oct%202015%2007:28:00%20GMT&max_age=0&samesite=none&secure=true"),
+			result{302, []string{"a=1; Path=/; Expires=Wed, 21 Oct 2015 07:28:00 GMT; Max-Age=0; Secure; SameSite=None"}},
+		},
+		{"TestSetCookiesHandler5", createTestCase("/cookies/set?a=1&expires=2015-10-21T07:28:00Z"), result{302, []string{"a=1; Path=/; Expires=Wed, 21 Oct 2015 07:28:00 GMT"}}},
+		{"TestSetCookiesHandler6", createTestCase("/cookies/set?a=1&samesite=sometimes"), result{400, nil}},
+		{"TestSetCookiesHandler7", createTestCase("/cookies/set?a=1&max_age=abc"), result{400, nil}},
+		{"TestSetCookiesHandler8", createTestCase("/cookies/set?a=1&partitioned"), result{400, nil}},
+		{"TestSetCookiesHandler9", createTestCase("/cookies/set/a"), result{404, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetCookiesHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if tt.result.code == http.StatusFound && tt.args.w.Header().Get("Location") != "/cookies" {
+				t.Errorf("handler returned wrong location header: got %v want /cookies",
+					tt.args.w.Header().Get("Location"))
+			}
+			cookies := tt.args.w.Header()["Set-Cookie"]
+			sort.Strings(cookies)
+			if !reflect.DeepEqual(cookies, tt.result.cookies) {
+				t.Errorf("handler returned wrong Set-Cookie headers: got %q want %q",
+					cookies, tt.result.cookies)
+			}
+		})
+	}
+}
+
+func TestDeleteCookiesHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(path string) args {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code    int
+		cookies []string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestDeleteCookiesHandler1", createTestCase("/cookies/delete?a&b"), result{302, []string{
+			"a=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0",
+			"b=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0",
+		}}},
+		{"TestDeleteCookiesHandler2", createTestCase("/cookies/delete?a&path=/cookies&domain=example.com"), result{302, []string{
+			"a=; Path=/cookies; Domain=example.com; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0",
+		}}},
+		{"TestDeleteCookiesHandler3", createTestCase("/cookies/delete?a&secure=maybe"), result{400, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			DeleteCookiesHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			cookies := tt.args.w.Header()["Set-Cookie"]
+			sort.Strings(cookies)
+			if !reflect.DeepEqual(cookies, tt.result.cookies) {
+				t.Errorf("handler returned wrong Set-Cookie headers: got %q want %q",
+					cookies, tt.result.cookies)
+			}
+		})
+	}
+}
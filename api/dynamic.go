@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseSeconds parses a non-negative, possibly fractional, number of seconds
+// capped to max.
+func parseSeconds(s string, max time.Duration) (time.Duration, bool) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil || !(seconds >= 0) {
+		return 0, false
+	}
+	if seconds > max.Seconds() {
+		return max, true
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// sleep waits for d, returning false if the client went away before.
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return r.Context().Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func DelayHandler(w http.ResponseWriter, r *http.Request) {
+	maxDelay := getOptions(r).MaxDelay
+	paths := strings.Split(r.URL.Path, "/")
+	if len(paths) != 3 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	delay, ok := parseSeconds(paths[2], maxDelay)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if s := r.URL.Query().Get("jitter"); s != "" {
+		jitter, ok := parseSeconds(s, maxDelay)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		delay += time.Duration(rand.Int63n(int64(2*jitter)+1)) - jitter
+		if delay < 0 {
+			delay = 0
+		}
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if !sleep(r, delay) {
+		return
+	}
+	methodsHander(&w, r)
+}
+
+// maxBytes caps the number of bytes /drip, /bytes and /stream-bytes write.
+const maxBytes = 10 * 1024 * 1024
+
+func DripHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	numBytes := 10
+	if s := query.Get("numbytes"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if n > maxBytes {
+			n = maxBytes
+		}
+		numBytes = n
+	}
+	durations := map[string]time.Duration{"duration": 2 * time.Second, "delay": 2 * time.Second}
+	for key := range durations {
+		if s := query.Get(key); s != "" {
+			d, ok := parseSeconds(s, getOptions(r).MaxDelay)
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			durations[key] = d
+		}
+	}
+	status := http.StatusOK
+	if s := query.Get("code"); s != "" {
+		statusCodeChoices, err := parseStatusCodes(s)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		status = randomStatusSelect(statusCodeChoices)
+	}
+	chunked, err := parseBool(query, "chunked")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !isHead(r) && !sleep(r, durations["delay"]) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if !chunked {
+		w.Header().Set("Content-Length", strconv.Itoa(numBytes))
+	}
+	w.WriteHeader(status)
+	if streamHead(w, r) {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	var interval time.Duration
+	if numBytes > 0 {
+		interval = durations["duration"] / time.Duration(numBytes)
+	}
+	// Byte i goes out at (i+1)*interval, so the last one ends the duration.
+	start := time.Now()
+	for i := 0; i < numBytes; i++ {
+		if !sleep(r, time.Until(start.Add(time.Duration(i+1)*interval))) {
+			return
+		}
+		if _, err := w.Write([]byte("*")); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// maxStreamLines caps the number of lines /stream writes.
+const maxStreamLines = 100
+
+type streamJSONResponse struct {
+	ID int `json:"id"`
+	methodsGETJSONResponse
+}
+
+func StreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	paths := strings.Split(r.URL.Path, "/")
+	if len(paths) != 3 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	n, err := strconv.Atoi(paths[2])
+	if err != nil || n < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if n > maxStreamLines {
+		n = maxStreamLines
+	}
+
+	response := streamJSONResponse{methodsGETJSONResponse: methodsGETJSONResponse{
+		Args:        fmtQueryString(r),
+		Headers:     fmtHeaders(r),
+		HTTPVersion: r.Proto,
+		Origin:      getIP(r),
+		URL:         getFullURL(r),
+	}}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if streamHead(w, r) {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	for i := 0; i < n; i++ {
+		response.ID = i
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
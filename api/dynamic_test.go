@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDelayHandler(t *testing.T) {
+	options := &Options{MaxDelay: 200 * time.Millisecond}
+
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string, body string) args {
+		r, err := http.NewRequest(method, path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), withOptions(r, options)}
+	}
+	type result struct {
+		code     int
+		min, max time.Duration
+		data     string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestDelayHandler1", createTestCase("GET", "/delay/0", ""), result{200, 0, time.Second, ""}},
+		{"TestDelayHandler2", createTestCase("POST", "/delay/0.05", "abc"), result{200, 50 * time.Millisecond, time.Second, "abc"}},
+		{"TestDelayHandler3", createTestCase("PROPFIND", "/delay/10", ""), result{200, 200 * time.Millisecond, 2 * time.Second, ""}},
+		{"TestDelayHandler4", createTestCase("GET", "/delay/0.1?jitter=0.05", ""), result{200, 50 * time.Millisecond, 2 * time.Second, ""}},
+		{"TestDelayHandler5", createTestCase("GET", "/delay/-1", ""), result{400, 0, time.Second, ""}},
+		{"TestDelayHandler6", createTestCase("GET", "/delay/abc", ""), result{400, 0, time.Second, ""}},
+		{"TestDelayHandler7", createTestCase("GET", "/delay/1?jitter=NaN", ""), result{400, 0, time.Second, ""}},
+		{"TestDelayHandler8", createTestCase("GET", "/delay/1/2", ""), result{400, 0, time.Second, ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			DelayHandler(tt.args.w, tt.args.r)
+			elapsed := time.Since(start)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if elapsed < tt.result.min || elapsed > tt.result.max {
+				t.Errorf("handler returned after %v, want between %v and %v",
+					elapsed, tt.result.min, tt.result.max)
+			}
+			if tt.result.code == http.StatusOK {
+				var body methodsJSONResponse
+				json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+				if body.Data != tt.result.data {
+					t.Errorf("handler returned wrong response json body: got %v want %v",
+						body.Data, tt.result.data)
+				}
+			}
+		})
+	}
+
+	t.Run("TestDelayHandlerCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r, _ := http.NewRequest("GET", "/delay/10", nil)
+		r = r.WithContext(ctx)
+		w := httptest.NewRecorder()
+		time.AfterFunc(20*time.Millisecond, cancel)
+		start := time.Now()
+		DelayHandler(w, r)
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("handler didn't stop when the client went away: returned after %v", elapsed)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("handler wrote a body after the client went away: %v", w.Body.String())
+		}
+	})
+}
+
+func TestDripHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string) args {
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code          int
+		min, max      time.Duration
+		body          string
+		contentLength string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestDripHandler1", createTestCase("GET", "/drip?numbytes=5&duration=0.1&delay=0"), result{200, 100 * time.Millisecond, time.Second, "*****", "5"}},
+		{"TestDripHandler2", createTestCase("GET", "/drip?numbytes=3&duration=0&delay=0.05&code=418"), result{418, 50 * time.Millisecond, time.Second, "***", "3"}},
+		{"TestDripHandler3", createTestCase("GET", "/drip?numbytes=2&duration=0&delay=0&chunked"), result{200, 0, time.Second, "**", ""}},
+		{"TestDripHandler4", createTestCase("GET", "/drip?numbytes=0&duration=0&delay=0"), result{200, 0, time.Second, "", "0"}},
+		{"TestDripHandler5", createTestCase("GET", "/drip?numbytes=-1"), result{400, 0, time.Second, "", ""}},
+		{"TestDripHandler6", createTestCase("GET", "/drip?duration=abc"), result{400, 0, time.Second, "", ""}},
+		{"TestDripHandler7", createTestCase("GET", "/drip?code=abc"), result{400, 0, time.Second, "", ""}},
+		{"TestDripHandler8", createTestCase("GET", "/drip?code=42"), result{400, 0, time.Second, "", ""}},
+		{"TestDripHandler9", createTestCase("GET", "/drip?chunked=maybe"), result{400, 0, time.Second, "", ""}},
+		{"TestDripHandler10", createTestCase("POST", "/drip"), result{405, 0, time.Second, "", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			DripHandler(tt.args.w, tt.args.r)
+			elapsed := time.Since(start)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if elapsed < tt.result.min || elapsed > tt.result.max {
+				t.Errorf("handler returned after %v, want between %v and %v",
+					elapsed, tt.result.min, tt.result.max)
+			}
+			if body := tt.args.w.Body.String(); body != tt.result.body {
+				t.Errorf("handler returned wrong body: got %q want %q",
+					body, tt.result.body)
+			}
+			if contentLength := tt.args.w.Header().Get("Content-Length"); contentLength != tt.result.contentLength {
+				t.Errorf("handler returned wrong Content-Length header: got %q want %q",
+					contentLength, tt.result.contentLength)
+			}
+		})
+	}
+
+	t.Run("TestDripHandlerChunked", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(DripHandler))
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/drip?numbytes=2&duration=0.4&delay=0&chunked")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.ContentLength != -1 || len(resp.TransferEncoding) == 0 || resp.TransferEncoding[0] != "chunked" {
+			t.Errorf("handler didn't use chunked framing: got %v %v", resp.ContentLength, resp.TransferEncoding)
+		}
+		// The second byte is only written 200ms after the first one.
+		buf := make([]byte, 2)
+		if n, err := resp.Body.Read(buf); err != nil || n != 1 {
+			t.Fatalf("handler didn't flush the first byte: got %v, %v", n, err)
+		}
+	})
+
+	t.Run("TestDripHandlerCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r, _ := http.NewRequest("GET", "/drip?numbytes=100&duration=10&delay=0", nil)
+		r = r.WithContext(ctx)
+		w := httptest.NewRecorder()
+		time.AfterFunc(20*time.Millisecond, cancel)
+		start := time.Now()
+		DripHandler(w, r)
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("handler didn't stop when the client went away: returned after %v", elapsed)
+		}
+		if w.Body.Len() >= 100 {
+			t.Errorf("handler kept writing after the client went away: wrote %v bytes", w.Body.Len())
+		}
+	})
+}
+
+func TestStreamHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string) args {
+		r, err := http.NewRequest(method, "http://example.com"+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code  int
+		lines int
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestStreamHandler1", createTestCase("GET", "/stream/3?a=1"), result{200, 3}},
+		{"TestStreamHandler2", createTestCase("GET", "/stream/0"), result{200, 0}},
+		{"TestStreamHandler3", createTestCase("GET", "/stream/1000"), result{200, 100}},
+		{"TestStreamHandler4", createTestCase("GET", "/stream/-1"), result{400, 0}},
+		{"TestStreamHandler5", createTestCase("GET", "/stream/abc"), result{400, 0}},
+		{"TestStreamHandler6", createTestCase("GET", "/stream/1/2"), result{400, 0}},
+		{"TestStreamHandler7", createTestCase("POST", "/stream/1"), result{405, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			StreamHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			var lines int
+			scanner := bufio.NewScanner(tt.args.w.Body)
+			for ; scanner.Scan(); lines++ {
+				var line streamJSONResponse
+				if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+					t.Fatalf("handler returned invalid json line %q: %v", scanner.Text(), err)
+				}
+				if line.ID != lines {
+					t.Errorf("handler returned wrong id: got %v want %v", line.ID, lines)
+				}
+				if line.HTTPVersion != "HTTP/1.1" {
+					t.Errorf("handler returned wrong http_version: got %v want HTTP/1.1", line.HTTPVersion)
+				}
+			}
+			if lines != tt.result.lines {
+				t.Errorf("handler returned wrong number of lines: got %v want %v",
+					lines, tt.result.lines)
+			}
+		})
+	}
+}
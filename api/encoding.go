@@ -0,0 +1,252 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxDecodedBodySize caps the size of compressed request bodies and of their
+// decompressed content.
+const maxDecodedBodySize = 32 * 1024 * 1024
+
+var (
+	errUnsupportedEncoding = errors.New("unsupported content encoding")
+	errBodyTooLarge        = errors.New("decoded body too large")
+)
+
+var contentDecoders = map[string]func(io.Reader) (io.ReadCloser, error){
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"x-gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	// deflate is zlib wrapped, but some clients send raw deflate data.
+	"deflate": func(r io.Reader) (io.ReadCloser, error) {
+		br := bufio.NewReader(r)
+		header, err := br.Peek(2)
+		if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	},
+	"br": func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	},
+	"zstd": func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+// encodingWriter is a compressing writer.
+type encodingWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+var contentEncoders = map[string]func(io.Writer) encodingWriter{
+	"gzip": func(w io.Writer) encodingWriter {
+		return gzip.NewWriter(w)
+	},
+	"deflate": func(w io.Writer) encodingWriter {
+		return zlib.NewWriter(w)
+	},
+	"br": func(w io.Writer) encodingWriter {
+		return brotli.NewWriter(w)
+	},
+	"zstd": func(w io.Writer) encodingWriter {
+		// NewWriter only fails on invalid options.
+		e, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		return e
+	},
+}
+
+// decodeBody replaces the body of r by its decoded content when it has a
+// Content-Encoding, returning the encoding and the size of the encoded body.
+func decodeBody(r *http.Request) (string, int, error) {
+	encoding := r.Header.Get("Content-Encoding")
+	var encodings []string
+	for _, e := range strings.Split(encoding, ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" && e != "identity" {
+			encodings = append(encodings, e)
+		}
+	}
+	if len(encodings) == 0 || r.Body == nil {
+		return "", 0, nil
+	}
+
+	encoded, err := ioutil.ReadAll(io.LimitReader(r.Body, maxDecodedBodySize+1))
+	if err != nil {
+		return "", 0, err
+	}
+	if len(encoded) > maxDecodedBodySize {
+		return "", 0, errBodyTooLarge
+	}
+	var body io.Reader = bytes.NewReader(encoded)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, ok := contentDecoders[encodings[i]]
+		if !ok {
+			return "", 0, errUnsupportedEncoding
+		}
+		decoded, err := decoder(body)
+		if err != nil {
+			return "", 0, err
+		}
+		defer decoded.Close()
+		body = decoded
+	}
+	decoded, err := ioutil.ReadAll(io.LimitReader(body, maxDecodedBodySize+1))
+	if err != nil {
+		return "", 0, err
+	}
+	if len(decoded) > maxDecodedBodySize {
+		return "", 0, errBodyTooLarge
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(decoded))
+	r.ContentLength = int64(len(decoded))
+	return encoding, len(encoded), nil
+}
+
+// encodingPreference breaks ties between encodings of the same quality.
+var encodingPreference = []string{"br", "zstd", "gzip", "deflate", "identity"}
+
+// negotiateEncoding returns the preferred encoding acceptable according to
+// the Accept-Encoding header, or "" when there is none.
+func negotiateEncoding(acceptEncoding string) string {
+	if strings.TrimSpace(acceptEncoding) == "" {
+		return "identity"
+	}
+	qualities := make(map[string]float64)
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(item, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(k) == "q" {
+				var err error
+				if q, err = strconv.ParseFloat(v, 64); err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+			}
+		}
+		if coding == "x-gzip" {
+			coding = "gzip"
+		}
+		qualities[coding] = q
+	}
+
+	var best string
+	var bestQ float64
+	for _, encoding := range encodingPreference {
+		q, ok := qualities[encoding]
+		if !ok {
+			if q, ok = qualities["*"]; !ok && encoding == "identity" {
+				q = 1
+			}
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// encodedResponseWriter compresses the response with encoding, unless it is
+// already encoded, has no body or serves byte ranges, which refer to the
+// identity encoding.
+type encodedResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+	writer   encodingWriter
+	started  bool
+}
+
+func (w *encodedResponseWriter) WriteHeader(status int) {
+	if w.started {
+		return
+	}
+	// Informational responses come before the final one, which is encoded.
+	if status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.started = true
+	header := w.Header()
+	if status != http.StatusNoContent &&
+		status != http.StatusNotModified && status != http.StatusPartialContent &&
+		header.Get("Content-Encoding") == "" &&
+		header.Get("Accept-Ranges") == "" && header.Get("Content-Range") == "" {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.writer = contentEncoders[w.encoding](w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *encodedResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.writer == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.writer.Write(b)
+}
+
+func (w *encodedResponseWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	if w.writer != nil {
+		w.writer.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *encodedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *encodedResponseWriter) close() {
+	if w.writer != nil {
+		w.writer.Close()
+	}
+}
+
+// withEncoding compresses the responses of handler with the encoding preferred
+// by the Accept-Encoding request header. It answers 406 when the client
+// accepts none of gzip, deflate, br, zstd and identity.
+func withEncoding(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		switch encoding {
+		case "":
+			w.WriteHeader(http.StatusNotAcceptable)
+		case "identity":
+			handler.ServeHTTP(w, r)
+		default:
+			ew := &encodedResponseWriter{ResponseWriter: w, encoding: encoding}
+			defer ew.close()
+			handler.ServeHTTP(ew, r)
+		}
+	})
+}
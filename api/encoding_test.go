@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		var err error
+		if w, err = zstd.NewWriter(&buf); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("unknown encoding %v", encoding)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecodeBody(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(encoding, contentType string, body []byte) args {
+		r, err := http.NewRequest("POST", "/post", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Encoding", encoding)
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	data := []byte(strings.Repeat("abcdefgh", 16))
+	jsonData := []byte(`{"a": "1"}`)
+	largeData := make([]byte, maxDecodedBodySize+1)
+	type result struct {
+		code           int
+		compressedSize int
+		data           string
+		json           interface{}
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestDecodeBody1", createTestCase("gzip", "", compress(t, "gzip", data)), result{200, len(compress(t, "gzip", data)), string(data), nil}},
+		{"TestDecodeBody2", createTestCase("deflate", "", compress(t, "deflate", data)), result{200, len(compress(t, "deflate", data)), string(data), nil}},
+		{"TestDecodeBody3", createTestCase("deflate", "", compress(t, "raw-deflate", data)), result{200, len(compress(t, "raw-deflate", data)), string(data), nil}},
+		{"TestDecodeBody4", createTestCase("br", "", compress(t, "br", data)), result{200, len(compress(t, "br", data)), string(data), nil}},
+		{"TestDecodeBody5", createTestCase("zstd", "", compress(t, "zstd", data)), result{200, len(compress(t, "zstd", data)), string(data), nil}},
+		{"TestDecodeBody6", createTestCase("gzip", "application/json", compress(t, "gzip", jsonData)), result{200, len(compress(t, "gzip", jsonData)), "", map[string]interface{}{"a": "1"}}},
+		{"TestDecodeBody7", createTestCase("deflate, gzip", "", compress(t, "gzip", compress(t, "deflate", data))), result{200, len(compress(t, "gzip", compress(t, "deflate", data))), string(data), nil}},
+		{"TestDecodeBody8", createTestCase("identity", "", data), result{200, 0, string(data), nil}},
+		{"TestDecodeBody9", createTestCase("gzip", "", data), result{400, 0, "", nil}},
+		{"TestDecodeBody10", createTestCase("compress", "", data), result{415, 0, "", nil}},
+		{"TestDecodeBody11", createTestCase("gzip", "", compress(t, "gzip", largeData)), result{413, 0, "", nil}},
+		{"TestDecodeBody12", createTestCase("gzip", "", largeData), result{413, 0, "", nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			POSTHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if tt.result.code != http.StatusOK {
+				return
+			}
+			var body methodsJSONResponse
+			json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+			if body.CompressedSize != tt.result.compressedSize {
+				t.Errorf("handler returned wrong compressed_size: got %v want %v",
+					body.CompressedSize, tt.result.compressedSize)
+			}
+			if tt.result.compressedSize != 0 && body.ContentEncoding != tt.args.r.Header.Get("Content-Encoding") {
+				t.Errorf("handler returned wrong content_encoding: got %v want %v",
+					body.ContentEncoding, tt.args.r.Header.Get("Content-Encoding"))
+			}
+			if body.Data != tt.result.data {
+				t.Errorf("handler returned wrong data: got %q want %q", body.Data, tt.result.data)
+			}
+			if !reflect.DeepEqual(body.JSON, tt.result.json) {
+				t.Errorf("handler returned wrong json: got %v want %v", body.JSON, tt.result.json)
+			}
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		result         string
+	}{
+		{"TestNegotiateEncoding1", "", "identity"},
+		{"TestNegotiateEncoding2", "gzip, deflate, br, zstd", "br"},
+		{"TestNegotiateEncoding3", "gzip;q=1.0, br;q=0.5", "gzip"},
+		{"TestNegotiateEncoding4", "deflate, x-gzip;q=0.9", "deflate"},
+		{"TestNegotiateEncoding5", "compress", "identity"},
+		{"TestNegotiateEncoding6", "*", "br"},
+		{"TestNegotiateEncoding7", "br;q=0, *;q=0.5", "zstd"},
+		{"TestNegotiateEncoding8", "identity;q=0", ""},
+		{"TestNegotiateEncoding9", "*;q=0", ""},
+		{"TestNegotiateEncoding10", "compress, *;q=0", ""},
+		{"TestNegotiateEncoding11", "gzip;q=abc", "identity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if encoding := negotiateEncoding(tt.acceptEncoding); encoding != tt.result {
+				t.Errorf("negotiateEncoding returned wrong encoding: got %q want %q", encoding, tt.result)
+			}
+		})
+	}
+
+	mux := http.NewServeMux()
+	if err := Route(mux, nil, &Options{NegotiateEncoding: true}); err != nil {
+		t.Fatal(err)
+	}
+	decompress := map[string]func(io.Reader) (io.ReadCloser, error){
+		"":        func(r io.Reader) (io.ReadCloser, error) { return ioutil.NopCloser(r), nil },
+		"gzip":    contentDecoders["gzip"],
+		"br":      contentDecoders["br"],
+		"zstd":    contentDecoders["zstd"],
+		"deflate": contentDecoders["deflate"],
+	}
+	type result struct {
+		code     int
+		encoding string
+	}
+	requests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		result         result
+	}{
+		{"TestNegotiateEncodingRoute1", "/get", "", result{200, ""}},
+		{"TestNegotiateEncodingRoute2", "/get", "gzip", result{200, "gzip"}},
+		{"TestNegotiateEncodingRoute3", "/get", "br, gzip", result{200, "br"}},
+		{"TestNegotiateEncodingRoute4", "/stream/3", "zstd", result{200, "zstd"}},
+		{"TestNegotiateEncodingRoute5", "/xml", "deflate", result{200, "deflate"}},
+		{"TestNegotiateEncodingRoute6", "/gzip", "br", result{200, "gzip"}},
+		{"TestNegotiateEncodingRoute7", "/status/204", "gzip", result{204, ""}},
+		{"TestNegotiateEncodingRoute8", "/get", "identity;q=0", result{406, ""}},
+		{"TestNegotiateEncodingRoute9", "/range/26", "gzip", result{200, ""}},
+	}
+	for _, tt := range requests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.path, nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.result.code)
+			}
+			if vary := w.Header().Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("handler returned wrong Vary header: got %q want Accept-Encoding", vary)
+			}
+			encoding := w.Header().Get("Content-Encoding")
+			if encoding != tt.result.encoding {
+				t.Errorf("handler returned wrong Content-Encoding header: got %q want %q", encoding, tt.result.encoding)
+			}
+			if tt.result.code != http.StatusOK {
+				return
+			}
+			reader, err := decompress[encoding](w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			if _, err := ioutil.ReadAll(reader); err != nil {
+				t.Errorf("handler returned a body that doesn't decode: %v", err)
+			}
+		})
+	}
+
+	t.Run("TestNegotiateEncodingInformational", func(t *testing.T) {
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		r, err := http.NewRequest("GET", server.URL+"/drip?code=103&numbytes=3&duration=0&delay=0", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Accept-Encoding", "gzip")
+		resp, err := server.Client().Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+		}
+		if encoding := resp.Header.Get("Content-Encoding"); encoding != "gzip" {
+			t.Errorf("handler returned wrong Content-Encoding header: got %q want %q", encoding, "gzip")
+		}
+	})
+}
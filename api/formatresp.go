@@ -169,6 +169,50 @@ func DeflateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writeEncodedJSON writes v as JSON compressed with encoding.
+func writeEncodedJSON(w http.ResponseWriter, encoding string, v interface{}) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Set("Content-Type", "application/json")
+	writer := contentEncoders[encoding](w)
+	defer writer.Close()
+	writer.Write(content)
+}
+
+type brotliJSONResponse struct {
+	Brotli  bool              `json:"brotli"`
+	Headers map[string]string `json:"headers"`
+	Method  string            `json:"method"`
+	Origin  string            `json:"origin"`
+}
+
+func BrotliHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeEncodedJSON(w, "br", brotliJSONResponse{true, fmtHeaders(r), r.Method, getIP(r)})
+}
+
+type zstdJSONResponse struct {
+	Zstd    bool              `json:"zstd"`
+	Headers map[string]string `json:"headers"`
+	Method  string            `json:"method"`
+	Origin  string            `json:"origin"`
+}
+
+func ZstdHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeEncodedJSON(w, "zstd", zstdJSONResponse{true, fmtHeaders(r), r.Method, getIP(r)})
+}
+
 func UTF8Handler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -10,6 +10,9 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 )
 
 func TestRobotTxtHandler(t *testing.T) {
@@ -263,6 +266,114 @@ func TestDeflateHandler(t *testing.T) {
 	}
 }
 
+func TestBrotliHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(name string) struct {
+		name   string
+		args   args
+		result brotliJSONResponse
+	} {
+		r, err := http.NewRequest("GET", "/brotli", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return struct {
+			name   string
+			args   args
+			result brotliJSONResponse
+		}{name, args{httptest.NewRecorder(), r}, brotliJSONResponse{true, fmtHeaders(r), r.Method, getIP(r)}}
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result brotliJSONResponse
+	}{
+		createTestCase("TestBrotliHandler1"),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			BrotliHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, http.StatusOK)
+			}
+			if encoding := tt.args.w.Header().Get("Content-Encoding"); encoding != "br" {
+				t.Errorf("handler returned wrong Content-Encoding header: got %v want br", encoding)
+			}
+			reader := brotli.NewReader(bytes.NewReader(tt.args.w.Body.Bytes()))
+			content, err := ioutil.ReadAll(reader)
+			if err != nil {
+				t.Error(err)
+			}
+			var body brotliJSONResponse
+			json.Unmarshal(content, &body)
+			if !reflect.DeepEqual(tt.result, body) {
+				t.Errorf("handler returned wrong response json body: got %v want %v",
+					body, tt.result)
+			}
+		})
+	}
+}
+
+func TestZstdHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(name string) struct {
+		name   string
+		args   args
+		result zstdJSONResponse
+	} {
+		r, err := http.NewRequest("GET", "/zstd", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return struct {
+			name   string
+			args   args
+			result zstdJSONResponse
+		}{name, args{httptest.NewRecorder(), r}, zstdJSONResponse{true, fmtHeaders(r), r.Method, getIP(r)}}
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result zstdJSONResponse
+	}{
+		createTestCase("TestZstdHandler1"),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ZstdHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, http.StatusOK)
+			}
+			if encoding := tt.args.w.Header().Get("Content-Encoding"); encoding != "zstd" {
+				t.Errorf("handler returned wrong Content-Encoding header: got %v want zstd", encoding)
+			}
+			reader, err := zstd.NewReader(bytes.NewReader(tt.args.w.Body.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			content, err := ioutil.ReadAll(reader)
+			if err != nil {
+				t.Error(err)
+			}
+			var body zstdJSONResponse
+			json.Unmarshal(content, &body)
+			if !reflect.DeepEqual(tt.result, body) {
+				t.Errorf("handler returned wrong response json body: got %v want %v",
+					body, tt.result)
+			}
+		})
+	}
+}
+
 func TestUTF8Handler(t *testing.T) {
 	type args struct {
 		w *httptest.ResponseRecorder
@@ -1,10 +1,16 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"strings"
+	"unicode/utf8"
 )
 
 func fmtQueryString(r *http.Request) map[string]interface{} {
@@ -34,11 +40,49 @@ func fmtForm(r *http.Request) map[string]interface{} {
 	return form
 }
 
-const maxMultipartFormSize = 0
+type multipartFileJSON struct {
+	Filename    string            `json:"filename"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	SHA256      string            `json:"sha256"`
+	Headers     map[string]string `json:"headers"`
+	Content     string            `json:"content"`
+	Encoding    string            `json:"encoding,omitempty"`
+}
+
+func fmtMultipartFile(fh *multipart.FileHeader) (multipartFileJSON, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return multipartFileJSON{}, err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return multipartFileJSON{}, err
+	}
+
+	file := multipartFileJSON{
+		Filename:    fh.Filename,
+		ContentType: fh.Header.Get("Content-Type"),
+		Size:        fh.Size,
+		SHA256:      fmt.Sprintf("%x", sha256.Sum256(data)),
+		Headers:     make(map[string]string),
+	}
+	for k, v := range fh.Header {
+		file.Headers[k] = strings.Join(v, ",")
+	}
+	if utf8.Valid(data) {
+		file.Content = string(data)
+	} else {
+		file.Content = base64.StdEncoding.EncodeToString(data)
+		file.Encoding = "base64"
+	}
+	return file, nil
+}
 
-func fmtMultipartForm(r *http.Request) (map[string]interface{}, map[string]interface{}) {
-	if err := r.ParseMultipartForm(maxMultipartFormSize); err != nil {
-		return nil, nil
+func fmtMultipartForm(r *http.Request) (map[string]interface{}, map[string]interface{}, error) {
+	if err := r.ParseMultipartForm(getOptions(r).MaxMultipartMemory); err != nil {
+		return nil, nil, err
 	}
 	form := make(map[string]interface{})
 	files := make(map[string]interface{})
@@ -50,21 +94,33 @@ func fmtMultipartForm(r *http.Request) (map[string]interface{}, map[string]inter
 		}
 	}
 	for k, v := range r.MultipartForm.File {
-		if len(v) == 1 {
-			f, _ := v[0].Open()
-			data, _ := ioutil.ReadAll(f)
-			files[k] = string(data)
-		} else {
-			var datas []string
-			for _, vv := range v {
-				f, _ := vv.Open()
-				data, _ := ioutil.ReadAll(f)
-				datas = append(datas, string(data))
+		var datas []multipartFileJSON
+		for _, vv := range v {
+			file, err := fmtMultipartFile(vv)
+			if err != nil {
+				return nil, nil, err
 			}
+			datas = append(datas, file)
+		}
+		if len(datas) == 1 {
+			files[k] = datas[0]
+		} else {
 			files[k] = datas
 		}
 	}
-	return form, files
+	return form, files, nil
+}
+
+// bodyErrorStatus returns the status code of the response to a request whose
+// body can't be read.
+func bodyErrorStatus(err error) int {
+	switch err {
+	case errUnsupportedEncoding:
+		return http.StatusUnsupportedMediaType
+	case errBodyTooLarge, multipart.ErrMessageTooLarge:
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
 }
 
 func getFullURL(r *http.Request) string {
@@ -76,10 +132,11 @@ func getFullURL(r *http.Request) string {
 }
 
 type methodsGETJSONResponse struct {
-	Args    map[string]interface{} `json:"args"`
-	Headers map[string]string      `json:"headers"`
-	Origin  string                 `json:"origin"`
-	URL     string                 `json:"url"`
+	Args        map[string]interface{} `json:"args"`
+	Headers     map[string]string      `json:"headers"`
+	HTTPVersion string                 `json:"http_version"`
+	Origin      string                 `json:"origin"`
+	URL         string                 `json:"url"`
 }
 
 func GETHandler(w http.ResponseWriter, r *http.Request) {
@@ -89,45 +146,74 @@ func GETHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(methodsGETJSONResponse{
-		Args:    fmtQueryString(r),
-		Headers: fmtHeaders(r),
-		Origin:  getIP(r),
-		URL:     getFullURL(r),
+		Args:        fmtQueryString(r),
+		Headers:     fmtHeaders(r),
+		HTTPVersion: r.Proto,
+		Origin:      getIP(r),
+		URL:         getFullURL(r),
 	})
 }
 
 type methodsJSONResponse struct {
-	Args    map[string]interface{} `json:"args"`
-	Data    string                 `json:"data"`
-	Files   map[string]interface{} `json:"files"`
-	Form    map[string]interface{} `json:"form"`
-	Headers map[string]string      `json:"headers"`
-	JSON    interface{}            `json:"json"`
-	Origin  string                 `json:"origin"`
-	URL     string                 `json:"url"`
+	Args            map[string]interface{} `json:"args"`
+	CompressedSize  int                    `json:"compressed_size,omitempty"`
+	ContentEncoding string                 `json:"content_encoding,omitempty"`
+	Data            string                 `json:"data"`
+	Files           map[string]interface{} `json:"files"`
+	Form            map[string]interface{} `json:"form"`
+	Headers         map[string]string      `json:"headers"`
+	HTTPVersion     string                 `json:"http_version"`
+	JSON            interface{}            `json:"json"`
+	JSONError       string                 `json:"json_error,omitempty"`
+	Origin          string                 `json:"origin"`
+	URL             string                 `json:"url"`
 }
 
-func methodsHander(wp *http.ResponseWriter, r *http.Request) {
-	w := *wp
+func newMethodsJSONResponse(r *http.Request) (methodsJSONResponse, error) {
+	encoding, compressedSize, err := decodeBody(r)
+	if err != nil {
+		return methodsJSONResponse{}, err
+	}
 	response := methodsJSONResponse{
-		Args:    fmtQueryString(r),
-		Headers: fmtHeaders(r),
-		Origin:  getIP(r),
-		URL:     getFullURL(r),
+		Args:            fmtQueryString(r),
+		CompressedSize:  compressedSize,
+		ContentEncoding: encoding,
+		Headers:         fmtHeaders(r),
+		HTTPVersion:     r.Proto,
+		Origin:          getIP(r),
+		URL:             getFullURL(r),
 	}
-	contentType := r.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	switch {
-	case contentType == "application/x-www-form-urlencoded":
+	case mediaType == "application/x-www-form-urlencoded":
 		response.Form = fmtForm(r)
-	case strings.HasPrefix(contentType, "multipart/form-data"):
-		response.Form, response.Files = fmtMultipartForm(r)
-	case contentType == "application/json":
+	case mediaType == "multipart/form-data":
+		if response.Form, response.Files, err = fmtMultipartForm(r); err != nil {
+			return methodsJSONResponse{}, err
+		}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
 		body, _ := ioutil.ReadAll(r.Body)
-		json.Unmarshal(body, &response.JSON)
+		if len(body) == 0 {
+			break
+		}
+		if err := json.Unmarshal(body, &response.JSON); err != nil {
+			response.Data = string(body)
+			response.JSONError = err.Error()
+		}
 	default:
 		body, _ := ioutil.ReadAll(r.Body)
 		response.Data = string(body)
 	}
+	return response, nil
+}
+
+func methodsHander(wp *http.ResponseWriter, r *http.Request) {
+	w := *wp
+	response, err := newMethodsJSONResponse(r)
+	if err != nil {
+		w.WriteHeader(bodyErrorStatus(err))
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -163,3 +249,18 @@ func DELETEHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	methodsHander(&w, r)
 }
+
+type anythingJSONResponse struct {
+	Method string `json:"method"`
+	methodsJSONResponse
+}
+
+func AnythingHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := newMethodsJSONResponse(r)
+	if err != nil {
+		w.WriteHeader(bodyErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anythingJSONResponse{r.Method, response})
+}
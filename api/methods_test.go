@@ -3,10 +3,12 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"net/url"
 	"reflect"
 	"strings"
@@ -44,9 +46,10 @@ func TestGETHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/get?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/get?a=1\u0026a=2\u0026b=3",
 			},
 		},
 	}
@@ -71,6 +74,10 @@ func TestGETHandler(t *testing.T) {
 						body.Headers, tt.result.Headers)
 				}
 			}
+			if tt.result.HTTPVersion != body.HTTPVersion {
+				t.Errorf("handler returned wrong response json body: got %v want %v",
+					body.HTTPVersion, tt.result.HTTPVersion)
+			}
 			if tt.result.Origin != body.Origin {
 				t.Errorf("handler returned wrong response json body: got %v want %v",
 					body.Origin, tt.result.Origin)
@@ -120,9 +127,10 @@ func TestPOSTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
 				Form: map[string]interface{}{
 					"test1": []interface{}{"test1", "test1-1"},
 					"test2": "test2",
@@ -148,15 +156,26 @@ func TestPOSTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
 				Form: map[string]interface{}{
 					"test1": []interface{}{"test1", "test1-1"},
 					"test2": "test2",
 				},
 				Files: map[string]interface{}{
-					"testfile": "abc\n",
+					"testfile": map[string]interface{}{
+						"filename":     "testfile",
+						"content_type": "application/octet-stream",
+						"size":         float64(4),
+						"sha256":       "edeaaff3f1774ad2888673770c6d64097e391bc362d7d6fb34982ddf0efd18cb",
+						"headers": map[string]interface{}{
+							"Content-Disposition": `form-data; name="testfile"; filename="testfile"`,
+							"Content-Type":        "application/octet-stream",
+						},
+						"content": "abc\n",
+					},
 				},
 			},
 		},
@@ -168,10 +187,11 @@ func TestPOSTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
-				JSON:    map[string]interface{}{"a": "1"},
+				Headers:     map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
+				JSON:        map[string]interface{}{"a": "1"},
 			},
 		},
 		{
@@ -182,10 +202,11 @@ func TestPOSTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
-				Data:    "abcdefgh",
+				Headers:     map[string]string{"X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/post?a=1\u0026a=2\u0026b=3",
+				Data:        "abcdefgh",
 			},
 		},
 	}
@@ -243,9 +264,10 @@ func TestPUTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
 				Form: map[string]interface{}{
 					"test1": []interface{}{"test1", "test1-1"},
 					"test2": "test2",
@@ -271,15 +293,26 @@ func TestPUTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
 				Form: map[string]interface{}{
 					"test1": []interface{}{"test1", "test1-1"},
 					"test2": "test2",
 				},
 				Files: map[string]interface{}{
-					"testfile": "abc\n",
+					"testfile": map[string]interface{}{
+						"filename":     "testfile",
+						"content_type": "application/octet-stream",
+						"size":         float64(4),
+						"sha256":       "edeaaff3f1774ad2888673770c6d64097e391bc362d7d6fb34982ddf0efd18cb",
+						"headers": map[string]interface{}{
+							"Content-Disposition": `form-data; name="testfile"; filename="testfile"`,
+							"Content-Type":        "application/octet-stream",
+						},
+						"content": "abc\n",
+					},
 				},
 			},
 		},
@@ -291,10 +324,11 @@ func TestPUTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
-				JSON:    map[string]interface{}{"a": "1"},
+				Headers:     map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
+				JSON:        map[string]interface{}{"a": "1"},
 			},
 		},
 		{
@@ -305,10 +339,11 @@ func TestPUTHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
-				Data:    "abcdefgh",
+				Headers:     map[string]string{"X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/put?a=1\u0026a=2\u0026b=3",
+				Data:        "abcdefgh",
 			},
 		},
 	}
@@ -366,9 +401,10 @@ func TestPATCHHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
 				Form: map[string]interface{}{
 					"test1": []interface{}{"test1", "test1-1"},
 					"test2": "test2",
@@ -394,15 +430,26 @@ func TestPATCHHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
 				Form: map[string]interface{}{
 					"test1": []interface{}{"test1", "test1-1"},
 					"test2": "test2",
 				},
 				Files: map[string]interface{}{
-					"testfile": "abc\n",
+					"testfile": map[string]interface{}{
+						"filename":     "testfile",
+						"content_type": "application/octet-stream",
+						"size":         float64(4),
+						"sha256":       "edeaaff3f1774ad2888673770c6d64097e391bc362d7d6fb34982ddf0efd18cb",
+						"headers": map[string]interface{}{
+							"Content-Disposition": `form-data; name="testfile"; filename="testfile"`,
+							"Content-Type":        "application/octet-stream",
+						},
+						"content": "abc\n",
+					},
 				},
 			},
 		},
@@ -414,10 +461,11 @@ func TestPATCHHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
-				JSON:    map[string]interface{}{"a": "1"},
+				Headers:     map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
+				JSON:        map[string]interface{}{"a": "1"},
 			},
 		},
 		{
@@ -428,10 +476,11 @@ func TestPATCHHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
-				Data:    "abcdefgh",
+				Headers:     map[string]string{"X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/patch?a=1\u0026a=2\u0026b=3",
+				Data:        "abcdefgh",
 			},
 		},
 	}
@@ -489,10 +538,11 @@ func TestDELETEHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
-				Form:    make(map[string]interface{}),
+				Headers:     map[string]string{"Content-Type": "application/x-www-form-urlencoded", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
+				Form:        make(map[string]interface{}),
 			},
 		},
 		{
@@ -514,15 +564,26 @@ func TestDELETEHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
+				Headers:     map[string]string{"Content-Type": "multipart/form-data; boundary=1eecdfc9a2d50fa834599aad3e2fb433a5a87887435d5e026e1d651da96a", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
 				Form: map[string]interface{}{
 					"test1": []interface{}{"test1", "test1-1"},
 					"test2": "test2",
 				},
 				Files: map[string]interface{}{
-					"testfile": "abc\n",
+					"testfile": map[string]interface{}{
+						"filename":     "testfile",
+						"content_type": "application/octet-stream",
+						"size":         float64(4),
+						"sha256":       "edeaaff3f1774ad2888673770c6d64097e391bc362d7d6fb34982ddf0efd18cb",
+						"headers": map[string]interface{}{
+							"Content-Disposition": `form-data; name="testfile"; filename="testfile"`,
+							"Content-Type":        "application/octet-stream",
+						},
+						"content": "abc\n",
+					},
 				},
 			},
 		},
@@ -534,10 +595,11 @@ func TestDELETEHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
-				JSON:    map[string]interface{}{"a": "1"},
+				Headers:     map[string]string{"Content-Type": "application/json", "X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
+				JSON:        map[string]interface{}{"a": "1"},
 			},
 		},
 		{
@@ -548,10 +610,11 @@ func TestDELETEHandler(t *testing.T) {
 					"a": []interface{}{"1", "2"},
 					"b": "3",
 				},
-				Headers: map[string]string{"X-Test": "Test"},
-				Origin:  "127.0.0.1",
-				URL:     "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
-				Data:    "abcdefgh",
+				Headers:     map[string]string{"X-Test": "Test"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/delete?a=1\u0026a=2\u0026b=3",
+				Data:        "abcdefgh",
 			},
 		},
 	}
@@ -571,3 +634,228 @@ func TestDELETEHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestAnythingHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string, headers [][2]string, body io.Reader) args {
+		r, err := http.NewRequest(method, path, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RequestURI = path
+		r.Host = "localhost:1121"
+		r.RemoteAddr = "127.0.0.1:1121"
+		for _, item := range headers {
+			r.Header.Add(item[0], item[1])
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result anythingJSONResponse
+	}{
+		{
+			"TestAnythingHandler1",
+			createTestCase("GET", "/anything?a=1", nil, strings.NewReader("")),
+			anythingJSONResponse{"GET", methodsJSONResponse{
+				Args:        map[string]interface{}{"a": "1"},
+				Headers:     map[string]string{},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/anything?a=1",
+			}},
+		},
+		{
+			"TestAnythingHandler2",
+			createTestCase("PROPFIND", "/anything/a/b", [][2]string{{"Content-Type", "application/json"}}, strings.NewReader("{\"a\": \"1\"}")),
+			anythingJSONResponse{"PROPFIND", methodsJSONResponse{
+				Args:        map[string]interface{}{},
+				Headers:     map[string]string{"Content-Type": "application/json"},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/anything/a/b",
+				JSON:        map[string]interface{}{"a": "1"},
+			}},
+		},
+		{
+			"TestAnythingHandler3",
+			createTestCase("PURGE", "/anything", nil, strings.NewReader("abc")),
+			anythingJSONResponse{"PURGE", methodsJSONResponse{
+				Args:        map[string]interface{}{},
+				Headers:     map[string]string{},
+				HTTPVersion: "HTTP/1.1",
+				Origin:      "127.0.0.1",
+				URL:         "http://localhost:1121/anything",
+				Data:        "abc",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AnythingHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, http.StatusOK)
+			}
+			var body anythingJSONResponse
+			json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+			if !reflect.DeepEqual(tt.result, body) {
+				t.Errorf("handler returned wrong response json body: got %v want %v",
+					body, tt.result)
+			}
+		})
+	}
+}
+
+func TestMultipartFiles(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(files [][3]string) args {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		for _, file := range files {
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, file[0], file[1]))
+			h.Set("Content-Type", "image/png")
+			h.Set("X-Test", "Test")
+			ww, _ := w.CreatePart(h)
+			ww.Write([]byte(file[2]))
+		}
+		w.Close()
+		r, err := http.NewRequest("POST", "/post", &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Type", w.FormDataContentType())
+		return args{httptest.NewRecorder(), r}
+	}
+	file := func(filename, content, sha256, encoding string) multipartFileJSON {
+		return multipartFileJSON{
+			Filename:    filename,
+			ContentType: "image/png",
+			Size:        3,
+			SHA256:      sha256,
+			Headers: map[string]string{
+				"Content-Disposition": fmt.Sprintf(`form-data; name="f"; filename="%s"`, filename),
+				"Content-Type":        "image/png",
+				"X-Test":              "Test",
+			},
+			Content:  content,
+			Encoding: encoding,
+		}
+	}
+	abc := file("a.txt", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", "")
+	binary := file("b.png", "gP//", "498e54dba1065b13337edc44c44f4e501ab95fd25351895e00d53bdd2ac91a79", "base64")
+	tests := []struct {
+		name   string
+		args   args
+		result []multipartFileJSON
+	}{
+		{"TestMultipartFiles1", createTestCase([][3]string{{"f", "a.txt", "abc"}}), []multipartFileJSON{abc}},
+		{"TestMultipartFiles2", createTestCase([][3]string{{"f", "b.png", "\x80\xff\xff"}}), []multipartFileJSON{binary}},
+		{"TestMultipartFiles3", createTestCase([][3]string{{"f", "a.txt", "abc"}, {"f", "b.png", "\x80\xff\xff"}}), []multipartFileJSON{abc, binary}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			POSTHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, http.StatusOK)
+			}
+			var body struct {
+				Files map[string]json.RawMessage `json:"files"`
+			}
+			json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+			var files []multipartFileJSON
+			if len(tt.result) == 1 {
+				var file multipartFileJSON
+				json.Unmarshal(body.Files["f"], &file)
+				files = append(files, file)
+			} else {
+				json.Unmarshal(body.Files["f"], &files)
+			}
+			if len(files) != len(tt.result) {
+				t.Fatalf("handler returned wrong files: got %v want %v", files, tt.result)
+			}
+			for i := range files {
+				if !reflect.DeepEqual(files[i], tt.result[i]) {
+					t.Errorf("handler returned wrong file: got %+v want %+v", files[i], tt.result[i])
+				}
+			}
+		})
+	}
+
+	t.Run("TestMultipartFilesMalformed", func(t *testing.T) {
+		r, _ := http.NewRequest("POST", "/post", strings.NewReader("--x\r\nabc"))
+		r.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+		w := httptest.NewRecorder()
+		POSTHandler(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", w.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestContentTypes(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(contentType, body string) args {
+		r, err := http.NewRequest("POST", "/post", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Type", contentType)
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		data      string
+		form      map[string]interface{}
+		json      interface{}
+		jsonError bool
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestContentTypes1", createTestCase("application/json; charset=utf-8", `{"a": "1"}`), result{"", nil, map[string]interface{}{"a": "1"}, false}},
+		{"TestContentTypes2", createTestCase("application/vnd.api+json", `{"a": "1"}`), result{"", nil, map[string]interface{}{"a": "1"}, false}},
+		{"TestContentTypes3", createTestCase("Application/JSON", `[1]`), result{"", nil, []interface{}{float64(1)}, false}},
+		{"TestContentTypes4", createTestCase("application/x-www-form-urlencoded; charset=UTF-8", "a=1"), result{"", map[string]interface{}{"a": "1"}, nil, false}},
+		{"TestContentTypes5", createTestCase("application/json", `{"a": `), result{`{"a": `, nil, nil, true}},
+		{"TestContentTypes6", createTestCase("application/json", ""), result{"", nil, nil, false}},
+		{"TestContentTypes7", createTestCase("application/jsonp", `{"a": "1"}`), result{`{"a": "1"}`, nil, nil, false}},
+		{"TestContentTypes8", createTestCase("text/plain; charset=utf-8", "abc"), result{"abc", nil, nil, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			POSTHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, http.StatusOK)
+			}
+			var body methodsJSONResponse
+			json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+			if body.Data != tt.result.data {
+				t.Errorf("handler returned wrong data: got %q want %q", body.Data, tt.result.data)
+			}
+			if !reflect.DeepEqual(body.Form, tt.result.form) {
+				t.Errorf("handler returned wrong form: got %v want %v", body.Form, tt.result.form)
+			}
+			if !reflect.DeepEqual(body.JSON, tt.result.json) {
+				t.Errorf("handler returned wrong json: got %v want %v", body.JSON, tt.result.json)
+			}
+			if (body.JSONError != "") != tt.result.jsonError {
+				t.Errorf("handler returned wrong json_error: got %q", body.JSONError)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+)
+
+// Options configures the endpoints, so that servers with different settings
+// can run in the same process.
+type Options struct {
+	// HostAliases are the other host names, without ports, under which this
+	// server can be reached. /cross-host-redirect only sends clients to them,
+	// on the port of the request.
+	HostAliases []string
+	// PlainHTTPPort is the port of the plain HTTP listener, which
+	// /downgrade-redirect sends clients to.
+	PlainHTTPPort string
+	// ClientCAs is the pool that /client-cert verifies client certificates
+	// against. The system pool is used when it is nil.
+	ClientCAs *x509.CertPool
+	// Certificate is the certificate served over TLS, whose OCSP staple and
+	// signed certificate timestamps /tls reports.
+	Certificate *tls.Certificate
+	// MaxDelay caps the delays requested from /delay and /drip.
+	MaxDelay time.Duration
+	// NegotiateEncoding compresses the responses with the encoding preferred
+	// by the Accept-Encoding request header.
+	NegotiateEncoding bool
+	// MaxMultipartMemory is the number of bytes of multipart form data kept in
+	// memory, the rest is stored in temporary files.
+	MaxMultipartMemory int64
+}
+
+// DefaultOptions returns the options used when none are given.
+func DefaultOptions() *Options {
+	return &Options{
+		PlainHTTPPort:      "1121",
+		MaxDelay:           10 * time.Second,
+		MaxMultipartMemory: 32 << 20,
+	}
+}
+
+var defaultOptions = DefaultOptions()
+
+type optionsKey struct{}
+
+// Handler returns a handler passing the requests to handler with o.
+func (o *Options) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, withOptions(r, o))
+	})
+}
+
+// withOptions returns a shallow copy of r carrying options.
+func withOptions(r *http.Request, options *Options) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), optionsKey{}, options))
+}
+
+// getOptions returns the options of the endpoint serving r, or the default
+// ones when it has none.
+func getOptions(r *http.Request) *Options {
+	if options, ok := r.Context().Value(optionsKey{}).(*Options); ok {
+		return options
+	}
+	return defaultOptions
+}
@@ -2,21 +2,52 @@ package api
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
 )
 
-func getRedirectURL(r *http.Request, n int) string {
-	scheme := "http://"
+func getScheme(r *http.Request) string {
 	if r.TLS != nil {
-		scheme = "https://"
+		return "https://"
 	}
+	return "http://"
+}
+
+func getBaseURL(r *http.Request) string {
+	return getScheme(r) + r.Host
+}
+
+func getRedirectURL(r *http.Request, n int) string {
 	if n != 1 {
-		url := scheme + r.Host + r.RequestURI
+		url := getBaseURL(r) + r.RequestURI
 		return fmt.Sprintf("%s/%d", url[:strings.LastIndex(url, "/")], n-1)
 	}
-	return scheme + r.Host + "/get"
+	return getBaseURL(r) + "/get"
+}
+
+func getRelativeRedirectURL(n int) string {
+	if n != 1 {
+		return fmt.Sprintf("/relative-redirect/%d", n-1)
+	}
+	return "/get"
+}
+
+func getRedirectCount(r *http.Request) (int, bool) {
+	paths := strings.Split(r.URL.Path, "/")
+	pathsLength := len(paths)
+	if pathsLength != 3 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(paths[pathsLength-1])
+	if err != nil {
+		return 0, false
+	}
+	if n < 1 {
+		return 0, false
+	}
+	return n, true
 }
 
 func AbsoluteRedirectHandler(w http.ResponseWriter, r *http.Request) {
@@ -24,20 +55,158 @@ func AbsoluteRedirectHandler(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	paths := strings.Split(r.URL.Path, "/")
-	pathsLength := len(paths)
-	if pathsLength != 3 {
+	n, ok := getRedirectCount(r)
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	n, err := strconv.Atoi(paths[pathsLength-1])
-	if err != nil {
+	http.Redirect(w, r, getRedirectURL(r, n), http.StatusFound)
+}
+
+func RelativeRedirectHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	n, ok := getRedirectCount(r)
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	if n < 1 {
+	w.Header().Set("Location", getRelativeRedirectURL(n))
+	w.WriteHeader(http.StatusFound)
+}
+
+func RedirectHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	n, ok := getRedirectCount(r)
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	http.Redirect(w, r, getRedirectURL(r, n), http.StatusFound)
+	if strings.ToLower(r.URL.Query().Get("absolute")) == "true" {
+		location := getBaseURL(r) + "/get"
+		if n != 1 {
+			location = fmt.Sprintf("%s/absolute-redirect/%d", getBaseURL(r), n-1)
+		}
+		http.Redirect(w, r, location, http.StatusFound)
+		return
+	}
+	w.Header().Set("Location", getRelativeRedirectURL(n))
+	w.WriteHeader(http.StatusFound)
+}
+
+func RedirectToHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	location := query.Get("url")
+	if location == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	status := http.StatusFound
+	if statusCode := query.Get("status_code"); statusCode != "" {
+		var err error
+		status, err = strconv.Atoi(statusCode)
+		if err != nil || status < 300 || status > 399 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Location", location)
+	w.WriteHeader(status)
+}
+
+func getRedirectPath(r *http.Request) string {
+	if path := r.URL.Query().Get("path"); strings.HasPrefix(path, "/") {
+		return path
+	}
+	return "/headers"
+}
+
+func RedirectLoopHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusFound)
+}
+
+func RedirectCycleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	n, ok := getRedirectCount(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var step int
+	if s := r.URL.Query().Get("step"); s != "" {
+		var err error
+		step, err = strconv.Atoi(s)
+		if err != nil || step < 0 || step >= n {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Location", fmt.Sprintf("/redirect-cycle/%d?step=%d", n, (step+1)%n))
+	w.WriteHeader(http.StatusFound)
+}
+
+func CrossHostRedirectHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	aliases := getOptions(r).HostAliases
+	requestHost, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		requestHost, port = strings.Trim(r.Host, "[]"), ""
+	}
+	host := r.URL.Query().Get("host")
+	if host == "" {
+		for _, alias := range aliases {
+			if alias != requestHost {
+				host = alias
+				break
+			}
+		}
+	}
+	var found bool
+	for _, alias := range aliases {
+		if alias == host {
+			found = true
+			break
+		}
+	}
+	if !found {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// The alias is served by the listener the request came through.
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	w.Header().Set("Location", getScheme(r)+host+getRedirectPath(r))
+	w.WriteHeader(http.StatusFound)
+}
+
+func DowngradeRedirectHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	host := strings.Trim(r.Host, "[]")
+	if h, _, err := net.SplitHostPort(r.Host); err == nil {
+		host = h
+	}
+	w.Header().Set("Location", "http://"+net.JoinHostPort(host, getOptions(r).PlainHTTPPort)+getRedirectPath(r))
+	w.WriteHeader(http.StatusFound)
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/tls"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -42,3 +43,198 @@ func TestAbsoluteRedirectHandler(t *testing.T) {
 		}
 	}
 }
+
+func TestRelativeRedirectHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(path string) args {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RequestURI = path
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code     int
+		location string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestRelativeRedirectHandler1", createTestCase("/relative-redirect/3"), result{302, "/relative-redirect/2"}},
+		{"TestRelativeRedirectHandler2", createTestCase("/relative-redirect/1"), result{302, "/get"}},
+		{"TestRelativeRedirectHandler3", createTestCase("/relative-redirect/0"), result{400, ""}},
+		{"TestRelativeRedirectHandler4", createTestCase("/relative-redirect/abc"), result{400, ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RelativeRedirectHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if location := tt.args.w.Header().Get("Location"); location != tt.result.location {
+				t.Errorf("handler returned wrong location header: got %v want %v",
+					location, tt.result.location)
+			}
+		})
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(path string) args {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RequestURI = path
+		r.Host = "localhost:1121"
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code     int
+		location string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestRedirectHandler1", createTestCase("/redirect/3"), result{302, "/relative-redirect/2"}},
+		{"TestRedirectHandler2", createTestCase("/redirect/1"), result{302, "/get"}},
+		{"TestRedirectHandler3", createTestCase("/redirect/3?absolute=true"), result{302, "http://localhost:1121/absolute-redirect/2"}},
+		{"TestRedirectHandler4", createTestCase("/redirect/1?absolute=true"), result{302, "http://localhost:1121/get"}},
+		{"TestRedirectHandler5", createTestCase("/redirect/-1"), result{400, ""}},
+		{"TestRedirectHandler6", createTestCase("/redirect/1/2"), result{400, ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RedirectHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if location := tt.args.w.Header().Get("Location"); location != tt.result.location {
+				t.Errorf("handler returned wrong location header: got %v want %v",
+					location, tt.result.location)
+			}
+		})
+	}
+}
+
+func TestRedirectToHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(method, path string) args {
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args{httptest.NewRecorder(), r}
+	}
+	type result struct {
+		code     int
+		location string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestRedirectToHandler1", createTestCase("GET", "/redirect-to?url=http%3A%2F%2Fexample.com%2F"), result{302, "http://example.com/"}},
+		{"TestRedirectToHandler2", createTestCase("POST", "/redirect-to?url=/post&status_code=307"), result{307, "/post"}},
+		{"TestRedirectToHandler3", createTestCase("PUT", "/redirect-to?url=relative&status_code=308"), result{308, "relative"}},
+		{"TestRedirectToHandler4", createTestCase("POST", "/redirect-to?url=/get&status_code=303"), result{303, "/get"}},
+		{"TestRedirectToHandler5", createTestCase("GET", "/redirect-to?url=/get&status_code=200"), result{400, ""}},
+		{"TestRedirectToHandler6", createTestCase("GET", "/redirect-to?url=/get&status_code=abc"), result{400, ""}},
+		{"TestRedirectToHandler7", createTestCase("GET", "/redirect-to"), result{400, ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RedirectToHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if location := tt.args.w.Header().Get("Location"); location != tt.result.location {
+				t.Errorf("handler returned wrong location header: got %v want %v",
+					location, tt.result.location)
+			}
+		})
+	}
+}
+
+func TestRedirectScenarioHandlers(t *testing.T) {
+	options := &Options{HostAliases: []string{"localhost", "127.0.0.1", "::1"}, PlainHTTPPort: "1121"}
+
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(path string, secure bool) args {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Host = "localhost:1121"
+		if secure {
+			r.Host = "localhost:1443"
+			r.TLS = &tls.ConnectionState{}
+		}
+		return args{httptest.NewRecorder(), withOptions(r, options)}
+	}
+	createIPv6TestCase := func(path string, secure bool) args {
+		tt := createTestCase(path, secure)
+		tt.r.Host = "[::1]"
+		return tt
+	}
+	type result struct {
+		code     int
+		location string
+	}
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, r *http.Request)
+		args    args
+		result  result
+	}{
+		{"TestRedirectLoopHandler1", RedirectLoopHandler, createTestCase("/redirect-loop", false), result{302, "/redirect-loop"}},
+		{"TestRedirectLoopHandler2", RedirectLoopHandler, createTestCase("/redirect-loop?a=1", false), result{302, "/redirect-loop?a=1"}},
+		{"TestRedirectCycleHandler1", RedirectCycleHandler, createTestCase("/redirect-cycle/2", false), result{302, "/redirect-cycle/2?step=1"}},
+		{"TestRedirectCycleHandler2", RedirectCycleHandler, createTestCase("/redirect-cycle/2?step=1", false), result{302, "/redirect-cycle/2?step=0"}},
+		{"TestRedirectCycleHandler3", RedirectCycleHandler, createTestCase("/redirect-cycle/2?step=2", false), result{400, ""}},
+		{"TestRedirectCycleHandler4", RedirectCycleHandler, createTestCase("/redirect-cycle/0", false), result{400, ""}},
+		{"TestCrossHostRedirectHandler1", CrossHostRedirectHandler, createTestCase("/cross-host-redirect", false), result{302, "http://127.0.0.1:1121/headers"}},
+		{"TestCrossHostRedirectHandler2", CrossHostRedirectHandler, createTestCase("/cross-host-redirect?host=127.0.0.1&path=/bearer", true), result{302, "https://127.0.0.1:1443/bearer"}},
+		{"TestCrossHostRedirectHandler3", CrossHostRedirectHandler, createTestCase("/cross-host-redirect?host=example.com", false), result{400, ""}},
+		{"TestCrossHostRedirectHandler4", CrossHostRedirectHandler, createTestCase("/cross-host-redirect?host=127.0.0.1:1121", false), result{400, ""}},
+		{"TestCrossHostRedirectHandler5", CrossHostRedirectHandler, createIPv6TestCase("/cross-host-redirect?host=::1", false), result{302, "http://[::1]/headers"}},
+		{"TestDowngradeRedirectHandler1", DowngradeRedirectHandler, createTestCase("/downgrade-redirect", true), result{302, "http://localhost:1121/headers"}},
+		{"TestDowngradeRedirectHandler2", DowngradeRedirectHandler, createTestCase("/downgrade-redirect?path=/get", true), result{302, "http://localhost:1121/get"}},
+		{"TestDowngradeRedirectHandler3", DowngradeRedirectHandler, createIPv6TestCase("/downgrade-redirect", true), result{302, "http://[::1]:1121/headers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.handler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if location := tt.args.w.Header().Get("Location"); location != tt.result.location {
+				t.Errorf("handler returned wrong location header: got %v want %v",
+					location, tt.result.location)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// endpoint is a handler with the methods it accepts. HEAD is accepted with GET
+// and OPTIONS always.
+type endpoint struct {
+	methods []string
+	handler func(w http.ResponseWriter, r *http.Request)
+}
+
+var (
+	onlyGET = []string{"GET"}
+	// anyMethod passes every method, HEAD and OPTIONS included, to the handler.
+	anyMethod []string
+)
+
+// anyMethodAllow is the Allow header of OPTIONS responses from anyMethod
+// endpoints, which accept custom methods too.
+const anyMethodAllow = "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"
+
+var endpointGroups = map[string]map[string]endpoint{
+	"http-methods": {
+		"/anything":  {anyMethod, AnythingHandler},
+		"/anything/": {anyMethod, AnythingHandler},
+		"/delete":    {[]string{"DELETE"}, DELETEHandler},
+		"/get":       {onlyGET, GETHandler},
+		"/patch":     {[]string{"PATCH"}, PATCHHandler},
+		"/post":      {[]string{"POST"}, POSTHandler},
+		"/put":       {[]string{"PUT"}, PUTHandler},
+	},
+	"auth": {
+		"/basic-auth/":        {onlyGET, BasicAuthHander},
+		"/bearer":             {onlyGET, BearerAuthHander},
+		"/digest-auth/":       {onlyGET, DigestAuthHander},
+		"/hidden-basic-auth/": {onlyGET, HiddenBasicAuthHander},
+	},
+	"status-codes": {
+		"/status/": {anyMethod, StatusHander},
+	},
+	"request-inspection": {
+		"/headers":    {anyMethod, HeadersHander},
+		"/ip":         {anyMethod, IPHander},
+		"/user-agent": {anyMethod, UserAgentHander},
+	},
+	"cookies": {
+		"/cookies":        {onlyGET, CookiesHandler},
+		"/cookies/delete": {onlyGET, DeleteCookiesHandler},
+		"/cookies/set":    {onlyGET, SetCookiesHandler},
+		"/cookies/set/":   {onlyGET, SetCookiesHandler},
+	},
+	"tls": {
+		"/client-cert": {onlyGET, ClientCertHandler},
+		"/tls":         {onlyGET, TLSHandler},
+	},
+	"response-inspection": {
+		"/cache":            {onlyGET, CacheHandler},
+		"/cache/":           {onlyGET, CacheControlHandler},
+		"/etag/":            {onlyGET, ETagHandler},
+		"/response-headers": {[]string{"GET", "POST"}, ResponseHeadersHandler},
+	},
+	"response-formats": {
+		"/brotli":        {onlyGET, BrotliHandler},
+		"/deflate":       {onlyGET, DeflateHandler},
+		"/deny":          {onlyGET, DenyHandler},
+		"/encoding/utf8": {onlyGET, UTF8Handler},
+		"/gzip":          {onlyGET, GZipHandler},
+		"/html":          {onlyGET, HTMLHandler},
+		"/json":          {onlyGET, JsonHandler},
+		"/robots.txt":    {onlyGET, RobotTxtHandler},
+		"/xml":           {onlyGET, XMLHandler},
+		"/zstd":          {onlyGET, ZstdHandler},
+	},
+	"dynamic-data": {
+		"/bytes/":        {onlyGET, BytesHandler},
+		"/delay/":        {anyMethod, DelayHandler},
+		"/drip":          {onlyGET, DripHandler},
+		"/range/":        {onlyGET, RangeHandler},
+		"/stream-bytes/": {onlyGET, StreamBytesHandler},
+		"/stream/":       {onlyGET, StreamHandler},
+	},
+	"redirects": {
+		"/absolute-redirect/":  {onlyGET, AbsoluteRedirectHandler},
+		"/cross-host-redirect": {onlyGET, CrossHostRedirectHandler},
+		"/downgrade-redirect":  {onlyGET, DowngradeRedirectHandler},
+		"/redirect/":           {onlyGET, RedirectHandler},
+		"/redirect-cycle/":     {onlyGET, RedirectCycleHandler},
+		"/redirect-loop":       {onlyGET, RedirectLoopHandler},
+		"/redirect-to":         {anyMethod, RedirectToHandler},
+		"/relative-redirect/":  {onlyGET, RelativeRedirectHandler},
+	},
+}
+
+// Middleware wraps the handler registered for a pattern.
+type Middleware func(pattern string, handler http.Handler) http.Handler
+
+// EndpointGroups returns the names of the endpoint groups accepted by Route.
+func EndpointGroups() []string {
+	groups := make([]string, 0, len(endpointGroups))
+	for group := range endpointGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Route registers the endpoints of the given groups on mux, or of every group
+// when groups is empty, configured by options, or by DefaultOptions when nil.
+// Each handler is wrapped by the middlewares in order, which see the HEAD,
+// OPTIONS and 405 responses of the router as sent.
+func Route(mux *http.ServeMux, groups []string, options *Options, middlewares ...Middleware) error {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	if len(groups) == 0 {
+		groups = EndpointGroups()
+	}
+
+	routed := make(map[string]bool)
+	for _, group := range groups {
+		patterns, ok := endpointGroups[group]
+		if !ok {
+			return fmt.Errorf("unknown endpoint group %q", group)
+		}
+		if routed[group] {
+			continue
+		}
+		routed[group] = true
+		for pattern, endpoint := range patterns {
+			handler := options.Handler(http.HandlerFunc(endpoint.handler))
+			// Encoding comes first so that HEAD responses get the encoded length.
+			if options.NegotiateEncoding {
+				handler = withEncoding(handler)
+			}
+			handler = withMethods(endpoint.methods, handler)
+			for _, m := range middlewares {
+				handler = m(pattern, handler)
+			}
+			mux.Handle(pattern, handler)
+		}
+	}
+	return nil
+}
+
+type headKey struct{}
+
+// isHead reports whether r is the GET request withMethods makes to answer a
+// HEAD request, whose response body is discarded.
+func isHead(r *http.Request) bool {
+	head, _ := r.Context().Value(headKey{}).(bool)
+	return head
+}
+
+// streamHead flushes the headers of a streamed response when r answers a HEAD
+// request, so that the handler can skip generating the body.
+func streamHead(w http.ResponseWriter, r *http.Request) bool {
+	if !isHead(r) {
+		return false
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return true
+}
+
+// headResponseWriter discards the body of a GET response, counting its bytes
+// to answer a HEAD request with the same headers. Flushed responses are
+// streamed without a Content-Length, so none is computed for them.
+type headResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	length  int
+	flushed bool
+}
+
+func (w *headResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.length += len(b)
+	return len(b), nil
+}
+
+func (w *headResponseWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	w.flushed = true
+}
+
+func (w *headResponseWriter) finish() {
+	w.WriteHeader(http.StatusOK)
+	header := w.Header()
+	if !w.flushed && header.Get("Content-Length") == "" && header.Get("Transfer-Encoding") == "" &&
+		w.status >= 200 && w.status != http.StatusNoContent && w.status != http.StatusNotModified {
+		header.Set("Content-Length", strconv.Itoa(w.length))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// withMethods answers OPTIONS requests and requests with methods not in
+// methods itself, with an Allow header, and HEAD requests with the headers of
+// the GET response. With anyMethod, OPTIONS requests get the Allow header and
+// go to the handler.
+func withMethods(methods []string, handler http.Handler) http.Handler {
+	if methods == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" {
+				w.Header().Set("Allow", anyMethodAllow)
+			}
+			handler.ServeHTTP(w, r)
+		})
+	}
+	allowed := make(map[string]bool)
+	var allow []string
+	for _, method := range methods {
+		allowed[method] = true
+		allow = append(allow, method)
+		if method == "GET" {
+			allowed["HEAD"] = true
+			allow = append(allow, "HEAD")
+		}
+	}
+	allow = append(allow, "OPTIONS")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "OPTIONS":
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusOK)
+		case !allowed[r.Method]:
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.Method == "HEAD":
+			get := r.Clone(context.WithValue(r.Context(), headKey{}, true))
+			get.Method = "GET"
+			hw := &headResponseWriter{ResponseWriter: w}
+			handler.ServeHTTP(hw, get)
+			hw.finish()
+		default:
+			handler.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRoute(t *testing.T) {
+	type result struct {
+		err  bool
+		code int
+	}
+	tests := []struct {
+		name   string
+		groups []string
+		result result
+	}{
+		{"TestRoute1", nil, result{false, 200}},
+		{"TestRoute2", []string{"http-methods"}, result{false, 200}},
+		{"TestRoute3", []string{"http-methods", "http-methods"}, result{false, 200}},
+		{"TestRoute4", []string{"auth"}, result{false, 404}},
+		{"TestRoute5", []string{"auth", "nonexistent"}, result{true, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			err := Route(mux, tt.groups, nil)
+			if (err != nil) != tt.result.err {
+				t.Fatalf("Route returned wrong error: got %v", err)
+			}
+			if err != nil {
+				return
+			}
+			r, _ := http.NewRequest("GET", "/get", nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.result.code)
+			}
+		})
+	}
+}
+
+func TestRouteMethods(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := Route(mux, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		code          int
+		allow         string
+		contentLength string
+	}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		result result
+	}{
+		{"TestRouteMethods1", "HEAD", "/json", result{200, "", "366"}},
+		{"TestRouteMethods2", "HEAD", "/bytes/100", result{200, "", "100"}},
+		{"TestRouteMethods3", "HEAD", "/range/10", result{200, "", "10"}},
+		{"TestRouteMethods4", "HEAD", "/stream/2", result{200, "", ""}},
+		{"TestRouteMethods5", "HEAD", "/redirect/1", result{302, "", "0"}},
+		{"TestRouteMethods6", "HEAD", "/post", result{405, "POST, OPTIONS", ""}},
+		{"TestRouteMethods7", "OPTIONS", "/get", result{200, "GET, HEAD, OPTIONS", "0"}},
+		{"TestRouteMethods8", "OPTIONS", "/response-headers", result{200, "GET, HEAD, POST, OPTIONS", "0"}},
+		{"TestRouteMethods9", "POST", "/get", result{405, "GET, HEAD, OPTIONS", ""}},
+		{"TestRouteMethods10", "GET", "/delete", result{405, "DELETE, OPTIONS", ""}},
+		{"TestRouteMethods11", "PROPFIND", "/anything", result{200, "", ""}},
+		{"TestRouteMethods12", "OPTIONS", "/anything", result{200, anyMethodAllow, ""}},
+		{"TestRouteMethods13", "OPTIONS", "/status/418", result{418, anyMethodAllow, ""}},
+		{"TestRouteMethods14", "OPTIONS", "/headers", result{200, anyMethodAllow, ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.result.code)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.result.allow {
+				t.Errorf("handler returned wrong Allow header: got %q want %q", allow, tt.result.allow)
+			}
+			if tt.method == "HEAD" && w.Body.Len() != 0 {
+				t.Errorf("handler returned a body for HEAD: %q", w.Body.String())
+			}
+			if tt.result.contentLength != "" && w.Header().Get("Content-Length") != tt.result.contentLength {
+				t.Errorf("handler returned wrong Content-Length header: got %q want %q",
+					w.Header().Get("Content-Length"), tt.result.contentLength)
+			}
+		})
+	}
+
+	t.Run("TestRouteMethodsStreamed", func(t *testing.T) {
+		paths := []string{"/stream/2", "/stream-bytes/10000000", "/drip?delay=10&duration=10&chunked=true"}
+		for _, path := range paths {
+			start := time.Now()
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("HEAD", path, nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("handler returned wrong status code for %v: got %v want %v", path, w.Code, http.StatusOK)
+			}
+			if length := w.Header().Get("Content-Length"); length != "" {
+				t.Errorf("handler returned a Content-Length header for %v: %q", path, length)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("handler took %v to answer HEAD %v", elapsed, path)
+			}
+		}
+	})
+
+	t.Run("TestRouteMethodsNegotiated", func(t *testing.T) {
+		mux := http.NewServeMux()
+		if err := Route(mux, nil, &Options{NegotiateEncoding: true}); err != nil {
+			t.Fatal(err)
+		}
+		get := httptest.NewRequest("GET", "/json", nil)
+		get.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, get)
+		head := httptest.NewRequest("HEAD", "/json", nil)
+		head.Header.Set("Accept-Encoding", "gzip")
+		hw := httptest.NewRecorder()
+		mux.ServeHTTP(hw, head)
+		if encoding := hw.Header().Get("Content-Encoding"); encoding != "gzip" {
+			t.Errorf("handler returned wrong Content-Encoding header: got %q want %q", encoding, "gzip")
+		}
+		if length, want := hw.Header().Get("Content-Length"), strconv.Itoa(w.Body.Len()); length != want {
+			t.Errorf("handler returned wrong Content-Length header: got %q want %q", length, want)
+		}
+		if hw.Body.Len() != 0 {
+			t.Errorf("handler returned a body for HEAD: %q", hw.Body.String())
+		}
+	})
+}
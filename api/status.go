@@ -1,12 +1,16 @@
 package api
 
 import (
+	"errors"
 	"math/rand"
 	"net/http"
 	"strconv"
 	"strings"
 )
 
+// randomStatusSelect picks a status with a probability proportional to its
+// weight. r is in [0, totalWeight), so a status is picked when r falls below
+// its weight, and one weighing 0 never is.
 func randomStatusSelect(choices map[int]int) int {
 	var totalWeight int
 	for _, weight := range choices {
@@ -16,7 +20,7 @@ func randomStatusSelect(choices map[int]int) int {
 	var rStatus int
 	for status, weight := range choices {
 		r -= weight
-		if r <= 0 {
+		if r < 0 {
 			rStatus = status
 			break
 		}
@@ -24,6 +28,45 @@ func randomStatusSelect(choices map[int]int) int {
 	return rStatus
 }
 
+// parseStatusCodes parses a comma-separated list of status codes, each with
+// an optional ":weight" suffix, into weighted choices.
+func parseStatusCodes(statusCodesParam string) (map[int]int, error) {
+	statusCodeChoices := make(map[int]int)
+	var totalWeight int
+	for index, str := range strings.Split(statusCodesParam, ",") {
+		if i := strings.Index(str, ":"); i > -1 {
+			status, err := strconv.Atoi(str[:i])
+			if err != nil {
+				return nil, err
+			}
+			weight, err := strconv.Atoi(str[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			if weight < 0 {
+				return nil, errors.New("negative status code weight")
+			}
+			statusCodeChoices[status] = weight
+		} else {
+			status, err := strconv.Atoi(str)
+			if err != nil {
+				return nil, err
+			}
+			statusCodeChoices[status] = index + 1
+		}
+	}
+	for status, weight := range statusCodeChoices {
+		if status < 100 || status > 999 {
+			return nil, errors.New("invalid status code")
+		}
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return nil, errors.New("no status code to choose from")
+	}
+	return statusCodeChoices, nil
+}
+
 func StatusHander(w http.ResponseWriter, r *http.Request) {
 	paths := strings.Split(r.URL.Path, "/")
 	pathsLength := len(paths)
@@ -41,29 +84,10 @@ func StatusHander(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	statusCodesParam := paths[2]
-	statusCodeChoices := make(map[int]int)
-	for index, str := range strings.Split(statusCodesParam, ",") {
-		if i := strings.Index(str, ":"); i > -1 {
-			status, err := strconv.Atoi(str[:i])
-			if err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
-			weight, err := strconv.Atoi(str[i+1:])
-			if err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
-			statusCodeChoices[status] = weight
-		} else {
-			status, err := strconv.Atoi(str)
-			if err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
-			statusCodeChoices[status] = index + 1
-		}
+	statusCodeChoices, err := parseStatusCodes(paths[2])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
 	status := randomStatusSelect(statusCodeChoices)
 	w.Header().Set("Content-Type", "text/plain")
@@ -34,6 +34,11 @@ func TestStatusHander(t *testing.T) {
 		{"TestStatusHander9", createTestCase("/status/201:6,401,abc:3/"), []int{400}},
 		{"TestStatusHander10", createTestCase("/status/201:6,401,500:3/abc"), []int{400}},
 		{"TestStatusHander11", createTestCase("/status/201:6,401,500:3/404"), []int{400}},
+		{"TestStatusHander12", createTestCase("/status/99"), []int{400}},
+		{"TestStatusHander13", createTestCase("/status/1000"), []int{400}},
+		{"TestStatusHander14", createTestCase("/status/201:-1,500"), []int{400}},
+		{"TestStatusHander15", createTestCase("/status/201:0,500:0"), []int{400}},
+		{"TestStatusHander16", createTestCase("/status/201:0,500"), []int{500}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -53,3 +58,36 @@ func TestStatusHander(t *testing.T) {
 		}
 	}
 }
+
+func TestRandomStatusSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		choices map[int]int
+		result  map[int]float64
+	}{
+		{"TestRandomStatusSelect1", map[int]int{200: 1}, map[int]float64{200: 1}},
+		{"TestRandomStatusSelect2", map[int]int{200: 1, 500: 3}, map[int]float64{200: 0.25, 500: 0.75}},
+		{"TestRandomStatusSelect3", map[int]int{200: 0, 500: 1}, map[int]float64{500: 1}},
+		{"TestRandomStatusSelect4", map[int]int{200: 1, 404: 1, 500: 2}, map[int]float64{200: 0.25, 404: 0.25, 500: 0.5}},
+	}
+	const n = 10000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make(map[int]int)
+			for i := 0; i < n; i++ {
+				counts[randomStatusSelect(tt.choices)]++
+			}
+			for status := range counts {
+				if _, ok := tt.result[status]; !ok {
+					t.Errorf("randomStatusSelect returned unexpected status %v", status)
+				}
+			}
+			for status, share := range tt.result {
+				if got := float64(counts[status]) / n; got < share-0.05 || got > share+0.05 {
+					t.Errorf("randomStatusSelect returned %v with wrong frequency: got %v want %v",
+						status, got, share)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type certificateJSON struct {
+	Subject           string    `json:"subject"`
+	Issuer            string    `json:"issuer"`
+	Serial            string    `json:"serial"`
+	SHA256Fingerprint string    `json:"sha256_fingerprint"`
+	NotBefore         time.Time `json:"not_before"`
+	NotAfter          time.Time `json:"not_after"`
+	DNSNames          []string  `json:"dns_names"`
+	EmailAddresses    []string  `json:"email_addresses"`
+	IPAddresses       []string  `json:"ip_addresses"`
+	URIs              []string  `json:"uris"`
+}
+
+func fmtCertificate(cert *x509.Certificate) certificateJSON {
+	result := certificateJSON{
+		Subject:           cert.Subject.String(),
+		Issuer:            cert.Issuer.String(),
+		Serial:            fmt.Sprintf("%x", cert.SerialNumber),
+		SHA256Fingerprint: fmt.Sprintf("%x", sha256.Sum256(cert.Raw)),
+		NotBefore:         cert.NotBefore.UTC(),
+		NotAfter:          cert.NotAfter.UTC(),
+		DNSNames:          cert.DNSNames,
+		EmailAddresses:    cert.EmailAddresses,
+		IPAddresses:       []string{},
+		URIs:              []string{},
+	}
+	if result.DNSNames == nil {
+		result.DNSNames = []string{}
+	}
+	if result.EmailAddresses == nil {
+		result.EmailAddresses = []string{}
+	}
+	for _, ip := range cert.IPAddresses {
+		result.IPAddresses = append(result.IPAddresses, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		result.URIs = append(result.URIs, uri.String())
+	}
+	return result
+}
+
+func fmtCertificates(certs []*x509.Certificate) []certificateJSON {
+	result := []certificateJSON{}
+	for _, cert := range certs {
+		result = append(result, fmtCertificate(cert))
+	}
+	return result
+}
+
+func verifyClientCertificate(certs []*x509.Certificate, roots *x509.CertPool) ([][]*x509.Certificate, error) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	return certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+}
+
+type clientCertJSONResponse struct {
+	Presented         bool                `json:"presented"`
+	Verified          bool                `json:"verified"`
+	VerificationError string              `json:"verification_error"`
+	Certificate       *certificateJSON    `json:"certificate"`
+	PeerCertificates  []certificateJSON   `json:"peer_certificates"`
+	VerifiedChains    [][]certificateJSON `json:"verified_chains"`
+}
+
+func ClientCertHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.TLS == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	response := clientCertJSONResponse{
+		PeerCertificates: fmtCertificates(r.TLS.PeerCertificates),
+		VerifiedChains:   [][]certificateJSON{},
+	}
+	if len(r.TLS.PeerCertificates) > 0 {
+		response.Presented = true
+		certificate := fmtCertificate(r.TLS.PeerCertificates[0])
+		response.Certificate = &certificate
+
+		chains := r.TLS.VerifiedChains
+		if len(chains) == 0 {
+			var err error
+			chains, err = verifyClientCertificate(r.TLS.PeerCertificates, getOptions(r).ClientCAs)
+			if err != nil {
+				response.VerificationError = err.Error()
+			}
+		}
+		for _, chain := range chains {
+			response.VerifiedChains = append(response.VerifiedChains, fmtCertificates(chain))
+		}
+		response.Verified = len(chains) > 0
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+type tlsJSONResponse struct {
+	Version                     string   `json:"version"`
+	CipherSuite                 string   `json:"cipher_suite"`
+	Curve                       string   `json:"curve"`
+	NegotiatedProtocol          string   `json:"negotiated_protocol"`
+	ServerName                  string   `json:"server_name"`
+	DidResume                   bool     `json:"did_resume"`
+	ECHAccepted                 bool     `json:"ech_accepted"`
+	OCSPResponse                []byte   `json:"ocsp_response"`
+	SignedCertificateTimestamps [][]byte `json:"signed_certificate_timestamps"`
+}
+
+func TLSHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.TLS == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	response := tlsJSONResponse{
+		Version:                     tls.VersionName(r.TLS.Version),
+		CipherSuite:                 tls.CipherSuiteName(r.TLS.CipherSuite),
+		NegotiatedProtocol:          r.TLS.NegotiatedProtocol,
+		ServerName:                  r.TLS.ServerName,
+		DidResume:                   r.TLS.DidResume,
+		ECHAccepted:                 r.TLS.ECHAccepted,
+		SignedCertificateTimestamps: [][]byte{},
+	}
+	// The connection state only holds the ones a client received.
+	if certificate := getOptions(r).Certificate; certificate != nil {
+		response.OCSPResponse = certificate.OCSPStaple
+		if certificate.SignedCertificateTimestamps != nil {
+			response.SignedCertificateTimestamps = certificate.SignedCertificateTimestamps
+		}
+	}
+	if r.TLS.CurveID != 0 {
+		response.Curve = r.TLS.CurveID.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
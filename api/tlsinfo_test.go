@@ -0,0 +1,194 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func createTestCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		template.DNSNames = []string{commonName}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestClientCertHandler(t *testing.T) {
+	ca, caKey := createTestCertificate(t, "test CA", nil, nil)
+	client, _ := createTestCertificate(t, "client.test", ca, caKey)
+	otherCA, otherCAKey := createTestCertificate(t, "other CA", nil, nil)
+	stranger, _ := createTestCertificate(t, "stranger.test", otherCA, otherCAKey)
+	options := &Options{ClientCAs: x509.NewCertPool()}
+	options.ClientCAs.AddCert(ca)
+
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(state *tls.ConnectionState) args {
+		r, err := http.NewRequest("GET", "/client-cert", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.TLS = state
+		return args{httptest.NewRecorder(), withOptions(r, options)}
+	}
+	type result struct {
+		code      int
+		presented bool
+		verified  bool
+		subject   string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestClientCertHandler1", createTestCase(nil), result{400, false, false, ""}},
+		{"TestClientCertHandler2", createTestCase(&tls.ConnectionState{}), result{200, false, false, ""}},
+		{"TestClientCertHandler3", createTestCase(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{client}}), result{200, true, true, "CN=client.test"}},
+		{"TestClientCertHandler4", createTestCase(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{stranger}}), result{200, true, false, "CN=stranger.test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClientCertHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if tt.result.code != http.StatusOK {
+				return
+			}
+			var body clientCertJSONResponse
+			json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+			if body.Presented != tt.result.presented || body.Verified != tt.result.verified {
+				t.Errorf("handler returned wrong response json body: got presented=%v verified=%v want presented=%v verified=%v",
+					body.Presented, body.Verified, tt.result.presented, tt.result.verified)
+			}
+			if body.Presented && body.Verified != (body.VerificationError == "") {
+				t.Errorf("handler returned inconsistent verification error: %q", body.VerificationError)
+			}
+			if tt.result.presented && body.Certificate.Subject != tt.result.subject {
+				t.Errorf("handler returned wrong subject: got %v want %v",
+					body.Certificate.Subject, tt.result.subject)
+			}
+		})
+	}
+}
+
+func TestTLSHandler(t *testing.T) {
+	type args struct {
+		w *httptest.ResponseRecorder
+		r *http.Request
+	}
+	createTestCase := func(state *tls.ConnectionState, certificate *tls.Certificate) args {
+		r, err := http.NewRequest("GET", "/tls", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.TLS = state
+		return args{httptest.NewRecorder(), withOptions(r, &Options{Certificate: certificate})}
+	}
+	type result struct {
+		code     int
+		response *tlsJSONResponse
+	}
+	tests := []struct {
+		name   string
+		args   args
+		result result
+	}{
+		{"TestTLSHandler1", createTestCase(nil, nil), result{400, nil}},
+		{
+			"TestTLSHandler2",
+			createTestCase(&tls.ConnectionState{
+				Version:            tls.VersionTLS13,
+				CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+				CurveID:            tls.X25519,
+				NegotiatedProtocol: "h2",
+				ServerName:         "localhost",
+				DidResume:          true,
+			}, nil),
+			result{200, &tlsJSONResponse{
+				Version:                     "TLS 1.3",
+				CipherSuite:                 "TLS_AES_128_GCM_SHA256",
+				Curve:                       "X25519",
+				NegotiatedProtocol:          "h2",
+				ServerName:                  "localhost",
+				DidResume:                   true,
+				SignedCertificateTimestamps: [][]byte{},
+			}},
+		},
+		{
+			"TestTLSHandler3",
+			createTestCase(&tls.ConnectionState{
+				Version:     tls.VersionTLS12,
+				CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				// Set on client connections only.
+				OCSPResponse:                []byte("client"),
+				SignedCertificateTimestamps: [][]byte{[]byte("client")},
+			}, &tls.Certificate{
+				OCSPStaple:                  []byte("staple"),
+				SignedCertificateTimestamps: [][]byte{[]byte("sct")},
+			}),
+			result{200, &tlsJSONResponse{
+				Version:                     "TLS 1.2",
+				CipherSuite:                 "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+				OCSPResponse:                []byte("staple"),
+				SignedCertificateTimestamps: [][]byte{[]byte("sct")},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TLSHandler(tt.args.w, tt.args.r)
+			if status := tt.args.w.Code; status != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.result.code)
+			}
+			if tt.result.response != nil {
+				var body tlsJSONResponse
+				json.Unmarshal(tt.args.w.Body.Bytes(), &body)
+				if !reflect.DeepEqual(*tt.result.response, body) {
+					t.Errorf("handler returned wrong response json body: got %+v want %+v",
+						body, *tt.result.response)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "HTTPBIN_"
+
+type duration time.Duration
+
+func (d duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+type tlsConfig struct {
+	Addr     string     `json:"addr" yaml:"addr"`
+	Cert     string     `json:"cert" yaml:"cert"`
+	Key      string     `json:"key" yaml:"key"`
+	Hosts    stringList `json:"hosts" yaml:"hosts"`
+	CAOutput string     `json:"ca_output" yaml:"ca_output"`
+}
+
+type mtlsConfig struct {
+	Addr     string `json:"addr" yaml:"addr"`
+	ClientCA string `json:"client_ca" yaml:"client_ca"`
+	Require  bool   `json:"require" yaml:"require"`
+}
+
+type http2Config struct {
+	Enable bool `json:"enable" yaml:"enable"`
+	H2C    bool `json:"h2c" yaml:"h2c"`
+}
+
+type http3Config struct {
+	Addr string `json:"addr" yaml:"addr"`
+}
+
+type metricsConfig struct {
+	Enable bool   `json:"enable" yaml:"enable"`
+	Path   string `json:"path" yaml:"path"`
+}
+
+type multipartConfig struct {
+	MaxMemory int64 `json:"max_memory" yaml:"max_memory"`
+}
+
+type compressionConfig struct {
+	Negotiate bool `json:"negotiate" yaml:"negotiate"`
+}
+
+type corsConfig struct {
+	Enable bool `json:"enable" yaml:"enable"`
+}
+
+type dynamicConfig struct {
+	MaxDelay duration `json:"max_delay" yaml:"max_delay"`
+}
+
+type redirectsConfig struct {
+	HostAliases stringList `json:"host_aliases" yaml:"host_aliases"`
+}
+
+type timeoutsConfig struct {
+	Read          duration `json:"read" yaml:"read"`
+	ReadHeader    duration `json:"read_header" yaml:"read_header"`
+	Write         duration `json:"write" yaml:"write"`
+	Idle          duration `json:"idle" yaml:"idle"`
+	Shutdown      duration `json:"shutdown" yaml:"shutdown"`
+	ShutdownDelay duration `json:"shutdown_delay" yaml:"shutdown_delay"`
+}
+
+type logConfig struct {
+	Output string `json:"output" yaml:"output"`
+	Access string `json:"access" yaml:"access"`
+}
+
+type config struct {
+	Addr        string            `json:"addr" yaml:"addr"`
+	TLS         tlsConfig         `json:"tls" yaml:"tls"`
+	MTLS        mtlsConfig        `json:"mtls" yaml:"mtls"`
+	HTTP2       http2Config       `json:"http2" yaml:"http2"`
+	HTTP3       http3Config       `json:"http3" yaml:"http3"`
+	Timeouts    timeoutsConfig    `json:"timeouts" yaml:"timeouts"`
+	Groups      stringList        `json:"groups" yaml:"groups"`
+	Redirects   redirectsConfig   `json:"redirects" yaml:"redirects"`
+	Dynamic     dynamicConfig     `json:"dynamic" yaml:"dynamic"`
+	Log         logConfig         `json:"log" yaml:"log"`
+	Metrics     metricsConfig     `json:"metrics" yaml:"metrics"`
+	CORS        corsConfig        `json:"cors" yaml:"cors"`
+	Compression compressionConfig `json:"compression" yaml:"compression"`
+	Multipart   multipartConfig   `json:"multipart" yaml:"multipart"`
+}
+
+func defaultConfig() *config {
+	return &config{
+		Addr: "0.0.0.0:1121",
+		TLS: tlsConfig{
+			Hosts: stringList{"localhost", "127.0.0.1", "::1"},
+		},
+		HTTP2:   http2Config{Enable: true},
+		Dynamic: dynamicConfig{MaxDelay: duration(10 * time.Second)},
+		Timeouts: timeoutsConfig{
+			ReadHeader: duration(10 * time.Second),
+			Idle:       duration(2 * time.Minute),
+			Shutdown:   duration(30 * time.Second),
+		},
+		Log:       logConfig{Output: "stderr", Access: "combined"},
+		Metrics:   metricsConfig{Enable: true, Path: "/metrics"},
+		CORS:      corsConfig{Enable: true},
+		Multipart: multipartConfig{MaxMemory: 32 << 20},
+	}
+}
+
+func newFlagSet(cfg *config, configFile *string) *flag.FlagSet {
+	fs := flag.NewFlagSet("httpbin", flag.ContinueOnError)
+	fs.StringVar(configFile, "config", "", "path to a YAML or JSON config file")
+	fs.StringVar(&cfg.Addr, "addr", cfg.Addr, "listen address")
+	fs.StringVar(&cfg.TLS.Addr, "tls-addr", cfg.TLS.Addr, "TLS listen address, empty to disable TLS")
+	fs.StringVar(&cfg.TLS.Cert, "tls-cert", cfg.TLS.Cert, "TLS certificate file, empty to generate a self-signed certificate")
+	fs.StringVar(&cfg.TLS.Key, "tls-key", cfg.TLS.Key, "TLS private key file")
+	fs.Var(&cfg.TLS.Hosts, "tls-hosts", "comma-separated host names and IP addresses of the self-signed certificate")
+	fs.StringVar(&cfg.TLS.CAOutput, "tls-ca-output", cfg.TLS.CAOutput, "file to write the self-signed CA certificate to")
+	fs.StringVar(&cfg.MTLS.Addr, "mtls-addr", cfg.MTLS.Addr, "mutual TLS listen address, empty to disable mutual TLS")
+	fs.StringVar(&cfg.MTLS.ClientCA, "mtls-client-ca", cfg.MTLS.ClientCA, "PEM file of the CAs client certificates are verified against, empty to use the system pool")
+	fs.BoolVar(&cfg.MTLS.Require, "mtls-require", cfg.MTLS.Require, "reject connections without a client certificate")
+	fs.BoolVar(&cfg.HTTP2.Enable, "http2", cfg.HTTP2.Enable, "negotiate HTTP/2 over TLS")
+	fs.BoolVar(&cfg.HTTP2.H2C, "h2c", cfg.HTTP2.H2C, "serve cleartext HTTP/2 (prior knowledge and Upgrade) on the plain listener")
+	fs.StringVar(&cfg.HTTP3.Addr, "http3-addr", cfg.HTTP3.Addr, "HTTP/3 (QUIC) UDP listen address, empty to disable HTTP/3")
+	fs.TextVar(&cfg.Timeouts.Read, "read-timeout", cfg.Timeouts.Read, "maximum duration for reading an entire request, 0 means no timeout")
+	fs.TextVar(&cfg.Timeouts.ReadHeader, "read-header-timeout", cfg.Timeouts.ReadHeader, "maximum duration for reading request headers, 0 means no timeout")
+	fs.TextVar(&cfg.Timeouts.Write, "write-timeout", cfg.Timeouts.Write, "maximum duration before timing out writes of a response, 0 means no timeout")
+	fs.TextVar(&cfg.Timeouts.Idle, "idle-timeout", cfg.Timeouts.Idle, "maximum duration to wait for the next request on a keep-alive connection")
+	fs.TextVar(&cfg.Timeouts.Shutdown, "shutdown-timeout", cfg.Timeouts.Shutdown, "maximum duration to wait for in-flight requests on SIGINT or SIGTERM")
+	fs.TextVar(&cfg.Timeouts.ShutdownDelay, "shutdown-delay", cfg.Timeouts.ShutdownDelay, "duration to keep accepting requests after SIGINT or SIGTERM before draining")
+	fs.Var(&cfg.Groups, "groups", "comma-separated endpoint groups to enable, empty enables all")
+	fs.TextVar(&cfg.Dynamic.MaxDelay, "max-delay", cfg.Dynamic.MaxDelay, "maximum delay clients can ask from /delay and /drip")
+	fs.Var(&cfg.Redirects.HostAliases, "host-aliases", "comma-separated host names of this server for /cross-host-redirect, which keeps the request port")
+	fs.StringVar(&cfg.Log.Output, "log-output", cfg.Log.Output, "log destination: stderr, stdout or a file path")
+	fs.BoolVar(&cfg.Metrics.Enable, "metrics", cfg.Metrics.Enable, "expose Prometheus metrics")
+	fs.StringVar(&cfg.Metrics.Path, "metrics-path", cfg.Metrics.Path, "path of the Prometheus metrics endpoint")
+	fs.StringVar(&cfg.Log.Access, "access-log", cfg.Log.Access, "access log format: combined, json, logfmt or none")
+	fs.Int64Var(&cfg.Multipart.MaxMemory, "multipart-max-memory", cfg.Multipart.MaxMemory, "bytes of multipart form data kept in memory, the rest goes to temporary files")
+	fs.BoolVar(&cfg.Compression.Negotiate, "negotiate-encoding", cfg.Compression.Negotiate, "compress every response according to Accept-Encoding")
+	fs.BoolVar(&cfg.CORS.Enable, "cors", cfg.CORS.Enable, "add CORS headers to cross-origin responses and answer preflight requests")
+	return fs
+}
+
+func (cfg *config) load(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return json.Unmarshal(content, cfg)
+	}
+	return yaml.Unmarshal(content, cfg)
+}
+
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// loadConfig merges, from lowest to highest precedence, the defaults, the
+// config file, HTTPBIN_* environment variables and the command-line flags.
+// A single positional argument is still accepted as the listen address.
+func loadConfig(args []string) (*config, error) {
+	var configFile string
+	fs := newFlagSet(defaultConfig(), &configFile)
+	fs.SetOutput(ioutil.Discard)
+	// Only the config file location matters here, parse errors are
+	// reported by the second pass.
+	fs.Parse(args)
+	if configFile == "" {
+		configFile = os.Getenv(envName("config"))
+	}
+
+	cfg := defaultConfig()
+	if configFile != "" {
+		if err := cfg.load(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	fs = newFlagSet(cfg, &configFile)
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if value, ok := os.LookupEnv(envName(f.Name)); ok && err == nil {
+			err = fs.Set(f.Name, value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		cfg.Addr = fs.Arg(0)
+	}
+	if (cfg.TLS.Cert == "") != (cfg.TLS.Key == "") {
+		return nil, errors.New("TLS certificate and key must be given together")
+	}
+	for _, alias := range cfg.Redirects.HostAliases {
+		if _, _, err := net.SplitHostPort(alias); err == nil {
+			return nil, fmt.Errorf("host alias %q must not have a port", alias)
+		}
+	}
+	if cfg.Metrics.Enable {
+		if err := checkMetricsPath(cfg.Metrics.Path, cfg.Groups); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
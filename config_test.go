@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpbin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	createConfigFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	yamlFile := createConfigFile("httpbin.yaml", "addr: 127.0.0.1:8080\ntimeouts:\n  read: 5s\ngroups: [auth, redirects]\n")
+	jsonFile := createConfigFile("httpbin.json", `{"addr": "127.0.0.1:9090", "timeouts": {"write": "3s"}, "log": {"output": "stdout"}}`)
+
+	tests := []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		result func(*config)
+	}{
+		{"TestLoadConfig1", nil, nil, func(*config) {}},
+		{"TestLoadConfig2", []string{"0.0.0.0:80"}, nil, func(cfg *config) {
+			cfg.Addr = "0.0.0.0:80"
+		}},
+		{"TestLoadConfig3", []string{"-config", yamlFile}, nil, func(cfg *config) {
+			cfg.Addr = "127.0.0.1:8080"
+			cfg.Timeouts.Read = duration(5 * time.Second)
+			cfg.Groups = stringList{"auth", "redirects"}
+		}},
+		{"TestLoadConfig4", nil, map[string]string{"HTTPBIN_CONFIG": jsonFile}, func(cfg *config) {
+			cfg.Addr = "127.0.0.1:9090"
+			cfg.Timeouts.Write = duration(3 * time.Second)
+			cfg.Log.Output = "stdout"
+		}},
+		{"TestLoadConfig5", []string{"-config", yamlFile, "-addr", ":1122"}, map[string]string{"HTTPBIN_ADDR": ":1123", "HTTPBIN_GROUPS": "status-codes"}, func(cfg *config) {
+			cfg.Addr = ":1122"
+			cfg.Timeouts.Read = duration(5 * time.Second)
+			cfg.Groups = stringList{"status-codes"}
+		}},
+		{"TestLoadConfig6", []string{"-read-header-timeout", "1m", "-groups", "auth, status-codes"}, nil, func(cfg *config) {
+			cfg.Timeouts.ReadHeader = duration(time.Minute)
+			cfg.Groups = stringList{"auth", "status-codes"}
+		}},
+		{"TestLoadConfig7", []string{"-metrics=false", "-metrics-path", "/get"}, nil, func(cfg *config) {
+			cfg.Metrics.Enable = false
+			cfg.Metrics.Path = "/get"
+		}},
+		{"TestLoadConfig8", []string{"-multipart-max-memory", "1024"}, map[string]string{"HTTPBIN_NEGOTIATE_ENCODING": "true"}, func(cfg *config) {
+			cfg.Multipart.MaxMemory = 1024
+			cfg.Compression.Negotiate = true
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+			cfg, err := loadConfig(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := defaultConfig()
+			tt.result(result)
+			if !reflect.DeepEqual(cfg, result) {
+				t.Errorf("loadConfig returned wrong config: got %+v want %+v", cfg, result)
+			}
+		})
+	}
+}
+
+func TestLoadConfigError(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"TestLoadConfigError1", []string{"-read-timeout", "abc"}},
+		{"TestLoadConfigError2", []string{"-config", "/nonexistent/httpbin.yaml"}},
+		{"TestLoadConfigError3", []string{"-tls-addr", ":443", "-tls-cert", "cert.pem"}},
+		{"TestLoadConfigError4", []string{"-metrics-path", ""}},
+		{"TestLoadConfigError5", []string{"-metrics-path", "/get"}},
+		{"TestLoadConfigError6", []string{"-metrics-path", "/status/metrics"}},
+		{"TestLoadConfigError7", []string{"-host-aliases", "localhost,127.0.0.1:1121"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadConfig(tt.args); err == nil {
+				t.Errorf("loadConfig(%v) should return an error", tt.args)
+			}
+		})
+	}
+}
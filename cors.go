@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Query parameters overriding the CORS headers of a response. An empty value
+// omits the header, to reproduce misconfigured servers.
+const (
+	corsAllowOriginParam      = "cors_allow_origin"
+	corsAllowMethodsParam     = "cors_allow_methods"
+	corsAllowHeadersParam     = "cors_allow_headers"
+	corsAllowCredentialsParam = "cors_allow_credentials"
+	corsExposeHeadersParam    = "cors_expose_headers"
+	corsMaxAgeParam           = "cors_max_age"
+	corsPreflightStatusParam  = "cors_preflight_status"
+)
+
+const corsDefaultMethods = "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// corsHeader sets the header key to the query parameter param when it's given,
+// or to value otherwise. Empty values aren't set.
+func corsHeader(w http.ResponseWriter, query url.Values, param, key, value string) {
+	if values, ok := query[param]; ok {
+		value = values[0]
+	}
+	if value != "" {
+		w.Header().Set(key, value)
+	}
+}
+
+// withCORS adds CORS headers to the responses to cross-origin requests, and
+// answers preflight requests itself. By default any origin, method and header
+// is allowed, with credentials.
+func withCORS(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		if s := query.Get(corsAllowCredentialsParam); s != "" {
+			allow, err := strconv.ParseBool(s)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if allow {
+				query.Set(corsAllowCredentialsParam, "true")
+			} else {
+				query.Set(corsAllowCredentialsParam, "")
+			}
+		}
+		if s := query.Get(corsMaxAgeParam); s != "" {
+			if _, err := strconv.Atoi(s); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		status := http.StatusNoContent
+		if s := query.Get(corsPreflightStatusParam); s != "" {
+			var err error
+			status, err = strconv.Atoi(s)
+			if err != nil || status < 200 || status > 599 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Add("Vary", "Origin")
+		corsHeader(w, query, corsAllowOriginParam, "Access-Control-Allow-Origin", origin)
+		corsHeader(w, query, corsAllowCredentialsParam, "Access-Control-Allow-Credentials", "true")
+
+		if r.Method != "OPTIONS" || r.Header.Get("Access-Control-Request-Method") == "" {
+			corsHeader(w, query, corsExposeHeadersParam, "Access-Control-Expose-Headers", "")
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		corsHeader(w, query, corsAllowMethodsParam, "Access-Control-Allow-Methods", corsDefaultMethods)
+		corsHeader(w, query, corsAllowHeadersParam, "Access-Control-Allow-Headers", strings.TrimSpace(r.Header.Get("Access-Control-Request-Headers")))
+		corsHeader(w, query, corsMaxAgeParam, "Access-Control-Max-Age", "3600")
+		w.WriteHeader(status)
+	})
+}
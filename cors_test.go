@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWithCORS(t *testing.T) {
+	handler := withCORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	createRequest := func(method, path string, headers map[string]string) *http.Request {
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return r
+	}
+	origin := map[string]string{"Origin": "https://example.com"}
+	preflight := map[string]string{
+		"Origin":                         "https://example.com",
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "X-Test",
+	}
+	type result struct {
+		code    int
+		headers map[string]string
+	}
+	tests := []struct {
+		name   string
+		r      *http.Request
+		result result
+	}{
+		{"TestWithCORS1", createRequest("GET", "/get", nil), result{418, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}}},
+		{"TestWithCORS2", createRequest("GET", "/get", origin), result{418, map[string]string{
+			"Access-Control-Allow-Origin":      "https://example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "",
+			"Vary":                             "Origin",
+		}}},
+		{"TestWithCORS3", createRequest("GET", "/get?cors_allow_origin=*&cors_allow_credentials=false&cors_expose_headers=X-Test", origin), result{418, map[string]string{
+			"Access-Control-Allow-Origin":      "*",
+			"Access-Control-Allow-Credentials": "",
+			"Access-Control-Expose-Headers":    "X-Test",
+		}}},
+		{"TestWithCORS4", createRequest("OPTIONS", "/get", origin), result{418, map[string]string{
+			"Access-Control-Allow-Origin":  "https://example.com",
+			"Access-Control-Allow-Methods": "",
+		}}},
+		{"TestWithCORS5", createRequest("OPTIONS", "/put", preflight), result{204, map[string]string{
+			"Access-Control-Allow-Origin":      "https://example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     corsDefaultMethods,
+			"Access-Control-Allow-Headers":     "X-Test",
+			"Access-Control-Max-Age":           "3600",
+		}}},
+		{"TestWithCORS6", createRequest("OPTIONS", "/put?cors_allow_origin=&cors_allow_methods=GET&cors_allow_headers=&cors_max_age=0&cors_preflight_status=403", preflight), result{403, map[string]string{
+			"Access-Control-Allow-Origin":  "",
+			"Access-Control-Allow-Methods": "GET",
+			"Access-Control-Allow-Headers": "",
+			"Access-Control-Max-Age":       "0",
+		}}},
+		{"TestWithCORS7", createRequest("GET", "/get?cors_allow_credentials=maybe", origin), result{400, nil}},
+		{"TestWithCORS8", createRequest("OPTIONS", "/put?cors_max_age=abc", preflight), result{400, nil}},
+		{"TestWithCORS9", createRequest("OPTIONS", "/put?cors_preflight_status=42", preflight), result{400, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tt.r)
+			if w.Code != tt.result.code {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.result.code)
+			}
+			for k, v := range tt.result.headers {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("handler returned wrong %v header: got %q want %q", k, got, v)
+				}
+			}
+		})
+	}
+
+	t.Run("TestWithCORSPreflightVary", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, createRequest("OPTIONS", "/put", preflight))
+		want := []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}
+		if !reflect.DeepEqual(w.Header()["Vary"], want) {
+			t.Errorf("handler returned wrong Vary headers: got %q want %q", w.Header()["Vary"], want)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Haujilo/httpbin-go/api"
+)
+
+func createTestServer(t *testing.T, cfg *config, tls bool) *httptest.Server {
+	mux := http.NewServeMux()
+	if err := api.Route(mux, cfg.Groups, nil); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(nil)
+	server.Config = newServer("", withH2C(mux, cfg), cfg)
+	if tls {
+		server.EnableHTTP2 = cfg.HTTP2.Enable
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	return server
+}
+
+func getHTTPVersion(t *testing.T, client *http.Client, url string) string {
+	resp, err := client.Get(url + "/get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		HTTPVersion string `json:"http_version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return body.HTTPVersion
+}
+
+func TestHTTP2(t *testing.T) {
+	h2cConfig := defaultConfig()
+	h2cConfig.HTTP2.H2C = true
+	http1Config := defaultConfig()
+	http1Config.HTTP2.Enable = false
+
+	tests := []struct {
+		name   string
+		cfg    *config
+		tls    bool
+		client func(*httptest.Server) *http.Client
+		result string
+	}{
+		{"TestHTTP2TLS", defaultConfig(), true, (*httptest.Server).Client, "HTTP/2.0"},
+		{"TestHTTP2Disabled", http1Config, true, (*httptest.Server).Client, "HTTP/1.1"},
+		{"TestHTTP2Cleartext", defaultConfig(), false, (*httptest.Server).Client, "HTTP/1.1"},
+		{"TestH2CPriorKnowledge", h2cConfig, false, func(*httptest.Server) *http.Client {
+			protocols := new(http.Protocols)
+			protocols.SetUnencryptedHTTP2(true)
+			return &http.Client{Transport: &http.Transport{Protocols: protocols}}
+		}, "HTTP/2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(t, tt.cfg, tt.tls)
+			defer server.Close()
+			if version := getHTTPVersion(t, tt.client(server), server.URL); version != tt.result {
+				t.Errorf("handler returned wrong http_version: got %v want %v", version, tt.result)
+			}
+		})
+	}
+}
+
+func TestH2CUpgrade(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.HTTP2.H2C = true
+	server := createTestServer(t, cfg, false)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /get HTTP/1.1\r\n" +
+		"Host: " + server.Listener.Addr().String() + "\r\n" +
+		"Connection: Upgrade, HTTP2-Settings\r\n" +
+		"Upgrade: h2c\r\n" +
+		"HTTP2-Settings: AAMAAABkAARAAAAAAAIAAAAA\r\n\r\n"))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Upgrade") != "h2c" {
+		t.Errorf("server didn't switch to h2c: got %v %v", resp.Status, resp.Header)
+	}
+}
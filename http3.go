@@ -0,0 +1,24 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+func newHTTP3Server(addr string, handler http.Handler, certificate tls.Certificate) *http3.Server {
+	return &http3.Server{
+		Addr:      addr,
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: []tls.Certificate{certificate}}),
+	}
+}
+
+// withAltSvc advertises the HTTP/3 server to clients of the TCP listeners.
+func withAltSvc(handler http.Handler, server *http3.Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.SetQUICHeaders(w.Header())
+		handler.ServeHTTP(w, r)
+	})
+}
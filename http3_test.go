@@ -0,0 +1,53 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Haujilo/httpbin-go/api"
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestHTTP3(t *testing.T) {
+	certificate, caPEM, err := generateSelfSignedCertificate([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	if err := api.Route(mux, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newHTTP3Server(conn.LocalAddr().String(), mux, certificate)
+	go server.Serve(conn)
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+	transport := &http3.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+	if version := getHTTPVersion(t, client, "https://"+conn.LocalAddr().String()); version != "HTTP/3.0" {
+		t.Errorf("handler returned wrong http_version: got %v want %v", version, "HTTP/3.0")
+	}
+
+	tcpServer := httptest.NewServer(withAltSvc(mux, server))
+	defer tcpServer.Close()
+	resp, err := http.Get(tcpServer.URL + "/ip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	_, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+	if altSvc := resp.Header.Get("Alt-Svc"); !strings.Contains(altSvc, `h3=":`+port+`"`) {
+		t.Errorf("handler returned wrong Alt-Svc header: got %v want h3 on port %v", altSvc, port)
+	}
+}
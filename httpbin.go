@@ -1,25 +1,205 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
 	"log"
-	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/Haujilo/httpbin-go/api"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+func openLogOutput(output string) (io.Writer, error) {
+	switch output {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	}
+	return os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+}
+
+func newServer(addr string, handler http.Handler, cfg *config) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       time.Duration(cfg.Timeouts.Read),
+		ReadHeaderTimeout: time.Duration(cfg.Timeouts.ReadHeader),
+		WriteTimeout:      time.Duration(cfg.Timeouts.Write),
+		IdleTimeout:       time.Duration(cfg.Timeouts.Idle),
+		Protocols:         new(http.Protocols),
+	}
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetHTTP2(cfg.HTTP2.Enable)
+	return server
+}
+
+// withH2C lets the plain listener accept HTTP/2 both with prior knowledge
+// and through an "Upgrade: h2c" request.
+func withH2C(handler http.Handler, cfg *config) http.Handler {
+	if !cfg.HTTP2.H2C {
+		return handler
+	}
+	return h2c.NewHandler(handler, &http2.Server{IdleTimeout: time.Duration(cfg.Timeouts.Idle)})
+}
+
+type listener struct {
+	name     string
+	addr     string
+	serve    func() error
+	shutdown func(ctx context.Context) error
+}
+
+func newTCPListener(name string, server *http.Server) *listener {
+	serve := server.ListenAndServe
+	if server.TLSConfig != nil {
+		serve = func() error { return server.ListenAndServeTLS("", "") }
+	}
+	return &listener{name, server.Addr, serve, server.Shutdown}
+}
+
+// serve runs the listeners until one of them fails or a signal arrives. It
+// then keeps serving for the shutdown delay, stops accepting connections and
+// waits up to the shutdown timeout for in-flight requests to complete.
+func serve(listeners []*listener, cfg *config, signals <-chan os.Signal) error {
+	errs := make(chan error, 2*len(listeners))
+	for _, l := range listeners {
+		go func(l *listener) {
+			log.Printf("Starting httpbin %s %s", l.name, l.addr)
+			if err := l.serve(); err != http.ErrServerClosed {
+				errs <- fmt.Errorf("%s listener: %v", l.name, err)
+			}
+		}(l)
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-signals:
+		log.Printf("Received %v, shutting down", sig)
+	}
+
+	time.Sleep(time.Duration(cfg.Timeouts.ShutdownDelay))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeouts.Shutdown))
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, l := range listeners {
+		wg.Add(1)
+		go func(l *listener) {
+			defer wg.Done()
+			if err := l.shutdown(ctx); err != nil {
+				errs <- fmt.Errorf("%s listener: %v", l.name, err)
+			}
+		}(l)
+	}
+	wg.Wait()
+	// The serve goroutines may still be sending, so errs stays open; it has
+	// room for every send.
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
 }
 
 func main() {
-	addr := "0.0.0.0:1121"
-	if len(os.Args) > 1 {
-		addr = os.Args[1]
+	cfg, err := loadConfig(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
 	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	output, err := openLogOutput(cfg.Log.Output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.SetOutput(output)
+
+	options := api.DefaultOptions()
+	options.HostAliases = cfg.Redirects.HostAliases
+	options.MaxDelay = time.Duration(cfg.Dynamic.MaxDelay)
+	options.NegotiateEncoding = cfg.Compression.Negotiate
+	options.MaxMultipartMemory = cfg.Multipart.MaxMemory
+	if _, port, err := net.SplitHostPort(cfg.Addr); err == nil {
+		options.PlainHTTPPort = port
+	}
+
 	mux := http.NewServeMux()
-	route(mux)
+	var middlewares []api.Middleware
+	if cfg.Metrics.Enable {
+		m := newMetrics()
+		mux.Handle(cfg.Metrics.Path, m)
+		middlewares = append(middlewares, m.instrument)
+	}
+	if err := api.Route(mux, cfg.Groups, options, middlewares...); err != nil {
+		log.Fatal(err)
+	}
 
-	log.Println("Starting httpbin", addr)
-	log.Fatal(http.ListenAndServe(addr, mux))
+	var certificate tls.Certificate
+	if cfg.TLS.Addr != "" || cfg.MTLS.Addr != "" || cfg.HTTP3.Addr != "" {
+		certificate, err = loadCertificate(&cfg.TLS)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options.Certificate = &certificate
+	}
+
+	var routed http.Handler = mux
+	if cfg.CORS.Enable {
+		routed = withCORS(mux)
+	}
+	logged, err := withAccessLog(routed, cfg.Log.Access, output)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var listeners []*listener
+	handler := logged
+	if cfg.HTTP3.Addr != "" {
+		server := newHTTP3Server(cfg.HTTP3.Addr, logged, certificate)
+		handler = withAltSvc(handler, server)
+		listeners = append(listeners, &listener{"HTTP/3", server.Addr, server.ListenAndServe, server.Shutdown})
+	}
+
+	server := newServer(cfg.Addr, withH2C(handler, cfg), cfg)
+	listeners = append(listeners, newTCPListener("HTTP", server))
+
+	if cfg.TLS.Addr != "" {
+		server := newServer(cfg.TLS.Addr, handler, cfg)
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{certificate}}
+		listeners = append(listeners, newTCPListener("TLS", server))
+	}
+
+	// HTTP/3 isn't advertised here as the QUIC listener doesn't ask for
+	// client certificates.
+	if cfg.MTLS.Addr != "" {
+		server := newServer(cfg.MTLS.Addr, logged, cfg)
+		server.TLSConfig, err = newMTLSConfig(certificate, &cfg.MTLS)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options.ClientCAs = server.TLSConfig.ClientCAs
+		listeners = append(listeners, newTCPListener("mutual TLS", server))
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	if err := serve(listeners, cfg, signals); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Stopped httpbin")
 }
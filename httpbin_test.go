@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServeGracefulShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+	cfg := defaultConfig()
+	cfg.Timeouts.Shutdown = duration(5 * time.Second)
+	server := newServer(ln.Addr().String(), mux, cfg)
+	l := &listener{"HTTP", server.Addr, func() error { return server.Serve(ln) }, server.Shutdown}
+
+	signals := make(chan os.Signal, 1)
+	result := make(chan error, 1)
+	go func() { result <- serve([]*listener{l}, cfg, signals) }()
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get("http://" + server.Addr + "/slow")
+		if err != nil {
+			t.Error(err)
+			close(responses)
+			return
+		}
+		responses <- resp
+	}()
+	<-started
+	signals <- syscall.SIGTERM
+
+	if resp, ok := <-responses; ok {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("in-flight request returned wrong status code: got %v want %v",
+				resp.StatusCode, http.StatusOK)
+		}
+	}
+	if err := <-result; err != nil {
+		t.Errorf("serve returned an error: %v", err)
+	}
+	if _, err := http.Get("http://" + server.Addr + "/slow"); err == nil {
+		t.Error("server still accepts requests after shutdown")
+	}
+}
@@ -0,0 +1,112 @@
+// Package httpbintest starts the httpbin endpoints in-process for use in
+// tests, in the manner of net/http/httptest.
+package httpbintest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/Haujilo/httpbin-go/api"
+)
+
+type options struct {
+	groups      []string
+	disabled    map[string]bool
+	middlewares []api.Middleware
+	api         *api.Options
+}
+
+// Option configures the servers created by this package.
+type Option func(*options)
+
+// WithGroups enables only the given endpoint groups.
+func WithGroups(groups ...string) Option {
+	return func(o *options) {
+		o.groups = append(o.groups, groups...)
+	}
+}
+
+// WithoutGroups disables the given endpoint groups.
+func WithoutGroups(groups ...string) Option {
+	return func(o *options) {
+		for _, group := range groups {
+			o.disabled[group] = true
+		}
+	}
+}
+
+// WithMiddleware wraps each endpoint handler with m.
+func WithMiddleware(m api.Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, m)
+	}
+}
+
+// WithOptions configures the endpoints with options instead of
+// api.DefaultOptions.
+func WithOptions(apiOptions *api.Options) Option {
+	return func(o *options) {
+		o.api = apiOptions
+	}
+}
+
+// NewHandler returns a handler serving the endpoints selected by opts.
+func NewHandler(opts ...Option) (http.Handler, error) {
+	o := &options{disabled: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	groups := o.groups
+	if len(groups) == 0 {
+		groups = api.EndpointGroups()
+	}
+	var enabled []string
+	for _, group := range groups {
+		if !o.disabled[group] {
+			enabled = append(enabled, group)
+		}
+	}
+
+	mux := http.NewServeMux()
+	if len(enabled) == 0 {
+		return mux, nil
+	}
+	if err := api.Route(mux, enabled, o.api, o.middlewares...); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+func newUnstartedServer(opts []Option) *httptest.Server {
+	handler, err := NewHandler(opts...)
+	if err != nil {
+		panic("httpbintest: " + err.Error())
+	}
+	return httptest.NewUnstartedServer(handler)
+}
+
+// NewServer starts and returns a new plain HTTP server. The caller should
+// call Close when finished, to shut it down. It panics on unknown endpoint
+// groups.
+func NewServer(opts ...Option) *httptest.Server {
+	server := newUnstartedServer(opts)
+	server.Start()
+	return server
+}
+
+// NewTLSServer starts and returns a new HTTP/1.1 server using TLS. Use its
+// Client method to get a client trusting the server certificate.
+func NewTLSServer(opts ...Option) *httptest.Server {
+	server := newUnstartedServer(opts)
+	server.StartTLS()
+	return server
+}
+
+// NewHTTP2Server starts and returns a new server using TLS which negotiates
+// HTTP/2. Its Client method returns a client configured for HTTP/2.
+func NewHTTP2Server(opts ...Option) *httptest.Server {
+	server := newUnstartedServer(opts)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	return server
+}
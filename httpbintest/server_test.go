@@ -0,0 +1,112 @@
+package httpbintest
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Haujilo/httpbin-go/api"
+)
+
+func TestServers(t *testing.T) {
+	tests := []struct {
+		name   string
+		server func(...Option) *httptest.Server
+		result string
+	}{
+		{"TestNewServer", NewServer, "HTTP/1.1"},
+		{"TestNewTLSServer", NewTLSServer, "HTTP/1.1"},
+		{"TestNewHTTP2Server", NewHTTP2Server, "HTTP/2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.server()
+			defer server.Close()
+			resp, err := server.Client().Get(server.URL + "/get")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var body struct {
+				HTTPVersion string `json:"http_version"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			if body.HTTPVersion != tt.result {
+				t.Errorf("server returned wrong http_version: got %v want %v", body.HTTPVersion, tt.result)
+			}
+		})
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	type result struct {
+		get, status int
+	}
+	tests := []struct {
+		name   string
+		opts   []Option
+		result result
+	}{
+		{"TestNewHandler1", nil, result{200, 418}},
+		{"TestNewHandler2", []Option{WithGroups("status-codes")}, result{404, 418}},
+		{"TestNewHandler3", []Option{WithoutGroups("status-codes")}, result{200, 404}},
+		{"TestNewHandler4", []Option{WithGroups("status-codes"), WithoutGroups("status-codes")}, result{404, 404}},
+		{"TestNewHandler5", []Option{WithMiddleware(func(pattern string, handler http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			})
+		})}, result{202, 202}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := NewHandler(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, code := range map[string]int{"/get": tt.result.get, "/status/418": tt.result.status} {
+				r, _ := http.NewRequest("GET", path, nil)
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+				if w.Code != code {
+					t.Errorf("handler returned wrong status code for %v: got %v want %v", path, w.Code, code)
+				}
+			}
+		})
+	}
+
+	if _, err := NewHandler(WithGroups("nonexistent")); err == nil {
+		t.Error("NewHandler should reject unknown endpoint groups")
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	certificate := &tls.Certificate{OCSPStaple: []byte("staple")}
+	tests := []struct {
+		name   string
+		opts   []Option
+		result string
+	}{
+		{"TestWithOptions1", []Option{WithOptions(&api.Options{Certificate: certificate})}, "staple"},
+		{"TestWithOptions2", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewTLSServer(tt.opts...)
+			defer server.Close()
+			resp, err := server.Client().Get(server.URL + "/tls")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var body struct {
+				OCSPResponse []byte `json:"ocsp_response"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			if string(body.OCSPResponse) != tt.result {
+				t.Errorf("server returned wrong ocsp_response: got %q want %q", body.OCSPResponse, tt.result)
+			}
+		})
+	}
+}
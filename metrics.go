@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Haujilo/httpbin-go/api"
+)
+
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// standardMethods are the methods labelled by name, the others are counted as
+// OTHER so that clients can't create series at will.
+var standardMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "CONNECT": true, "OPTIONS": true, "TRACE": true,
+}
+
+func methodLabel(method string) string {
+	if standardMethods[method] {
+		return method
+	}
+	return "OTHER"
+}
+
+type requestKey struct {
+	method string
+	code   int
+}
+
+type routeMetrics struct {
+	requests     map[requestKey]uint64
+	inFlight     int64
+	bucketCounts []uint64
+	latencySum   float64
+	latencyCount uint64
+	bytesIn      int64
+	bytesOut     int64
+}
+
+// metrics collects per-route request counters and exposes them in the
+// Prometheus text format.
+type metrics struct {
+	mu     sync.Mutex
+	routes map[string]*routeMetrics
+}
+
+func newMetrics() *metrics {
+	return &metrics{routes: make(map[string]*routeMetrics)}
+}
+
+func (m *metrics) route(pattern string) *routeMetrics {
+	rm, ok := m.routes[pattern]
+	if !ok {
+		rm = &routeMetrics{
+			requests:     make(map[requestKey]uint64),
+			bucketCounts: make([]uint64, len(latencyBuckets)),
+		}
+		m.routes[pattern] = rm
+	}
+	return rm
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (m *metrics) instrument(pattern string, handler http.Handler) http.Handler {
+	m.mu.Lock()
+	m.route(pattern)
+	m.mu.Unlock()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.routes[pattern].inFlight++
+		m.mu.Unlock()
+
+		start := time.Now()
+		body := &countingReader{ReadCloser: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
+		recorder := &responseRecorder{ResponseWriter: w}
+		defer func() {
+			latency := time.Since(start).Seconds()
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			rm := m.routes[pattern]
+			rm.inFlight--
+			rm.requests[requestKey{methodLabel(r.Method), recorder.status}]++
+			for i, bound := range latencyBuckets {
+				if latency <= bound {
+					rm.bucketCounts[i]++
+				}
+			}
+			rm.latencySum += latency
+			rm.latencyCount++
+			rm.bytesIn += body.n
+			rm.bytesOut += recorder.bytes
+		}()
+		handler.ServeHTTP(recorder, r)
+	})
+}
+
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+	switch r.Method {
+	case "GET", "HEAD":
+	case "OPTIONS":
+		return
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var b bytes.Buffer
+	m.write(&b)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(b.Len()))
+	if r.Method == "HEAD" {
+		return
+	}
+	w.Write(b.Bytes())
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	patterns := make([]string, 0, len(m.routes))
+	for pattern := range m.routes {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	fmt.Fprintln(w, "# HELP httpbin_http_requests_total Total number of HTTP requests by route, method and status code.")
+	fmt.Fprintln(w, "# TYPE httpbin_http_requests_total counter")
+	for _, pattern := range patterns {
+		rm := m.routes[pattern]
+		keys := make([]requestKey, 0, len(rm.requests))
+		for key := range rm.requests {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].method != keys[j].method {
+				return keys[i].method < keys[j].method
+			}
+			return keys[i].code < keys[j].code
+		})
+		for _, key := range keys {
+			fmt.Fprintf(w, "httpbin_http_requests_total{route=%q,method=%q,code=\"%d\"} %d\n",
+				pattern, key.method, key.code, rm.requests[key])
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP httpbin_http_request_duration_seconds HTTP request latency by route.")
+	fmt.Fprintln(w, "# TYPE httpbin_http_request_duration_seconds histogram")
+	for _, pattern := range patterns {
+		rm := m.routes[pattern]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(w, "httpbin_http_request_duration_seconds_bucket{route=%q,le=%q} %d\n",
+				pattern, strconv.FormatFloat(bound, 'g', -1, 64), rm.bucketCounts[i])
+		}
+		fmt.Fprintf(w, "httpbin_http_request_duration_seconds_bucket{route=%q,le=\"+Inf\"} %d\n", pattern, rm.latencyCount)
+		fmt.Fprintf(w, "httpbin_http_request_duration_seconds_sum{route=%q} %g\n", pattern, rm.latencySum)
+		fmt.Fprintf(w, "httpbin_http_request_duration_seconds_count{route=%q} %d\n", pattern, rm.latencyCount)
+	}
+
+	series := []struct {
+		name, help, kind string
+		value            func(*routeMetrics) int64
+	}{
+		{"httpbin_http_request_bytes_total", "Total bytes read from request bodies by route.", "counter",
+			func(rm *routeMetrics) int64 { return rm.bytesIn }},
+		{"httpbin_http_response_bytes_total", "Total bytes written to response bodies by route.", "counter",
+			func(rm *routeMetrics) int64 { return rm.bytesOut }},
+		{"httpbin_http_requests_in_flight", "Number of HTTP requests being served by route.", "gauge",
+			func(rm *routeMetrics) int64 { return rm.inFlight }},
+	}
+	for _, s := range series {
+		fmt.Fprintf(w, "# HELP %s %s\n", s.name, s.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", s.name, s.kind)
+		for _, pattern := range patterns {
+			fmt.Fprintf(w, "%s{route=%q} %d\n", s.name, pattern, s.value(m.routes[pattern]))
+		}
+	}
+}
+
+// checkMetricsPath returns an error when path isn't a plain path or is served
+// by one of the endpoint groups.
+func checkMetricsPath(path string, groups []string) error {
+	if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, " \t{}") {
+		return fmt.Errorf("invalid metrics path %q", path)
+	}
+	mux := http.NewServeMux()
+	if err := api.Route(mux, groups, nil); err != nil {
+		return err
+	}
+	if _, pattern := mux.Handler(&http.Request{Method: "GET", URL: &url.URL{Path: path}}); pattern != "" {
+		return fmt.Errorf("metrics path %q is served by %s", path, pattern)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Haujilo/httpbin-go/api"
+)
+
+func TestMetrics(t *testing.T) {
+	m := newMetrics()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	if err := api.Route(mux, []string{"http-methods", "status-codes"}, nil, m.instrument); err != nil {
+		t.Fatal(err)
+	}
+	requests := []struct {
+		method, path, body string
+	}{
+		{"GET", "/get", ""},
+		{"GET", "/get", ""},
+		{"POST", "/post", "abcdefgh"},
+		{"GET", "/status/418", ""},
+		{"PUT", "/status/418", ""},
+		{"PROPFIND", "/status/418", ""},
+		{"X-RANDOM-1234", "/status/418", ""},
+	}
+	for _, item := range requests {
+		r, err := http.NewRequest(item.method, item.path, strings.NewReader(item.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	r, err := http.NewRequest("GET", "/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	body, _ := ioutil.ReadAll(w.Body)
+	tests := []string{
+		`httpbin_http_requests_total{route="/get",method="GET",code="200"} 2`,
+		`httpbin_http_requests_total{route="/post",method="POST",code="200"} 1`,
+		`httpbin_http_requests_total{route="/status/",method="GET",code="418"} 1`,
+		`httpbin_http_requests_total{route="/status/",method="PUT",code="418"} 1`,
+		`httpbin_http_requests_total{route="/status/",method="OTHER",code="418"} 2`,
+		`httpbin_http_request_duration_seconds_bucket{route="/get",le="+Inf"} 2`,
+		`httpbin_http_request_duration_seconds_count{route="/status/"} 4`,
+		`httpbin_http_request_bytes_total{route="/post"} 8`,
+		`httpbin_http_requests_in_flight{route="/get"} 0`,
+		`httpbin_http_requests_in_flight{route="/delete"} 0`,
+	}
+	for _, tt := range tests {
+		if !strings.Contains(string(body), tt+"\n") {
+			t.Errorf("metrics endpoint doesn't contain %v", tt)
+		}
+	}
+	if !strings.Contains(string(body), `httpbin_http_response_bytes_total{route="/post"} `) ||
+		strings.Contains(string(body), `httpbin_http_response_bytes_total{route="/post"} 0`) {
+		t.Error("metrics endpoint doesn't count response bytes")
+	}
+}
+
+func TestMetricsRouterMethods(t *testing.T) {
+	m := newMetrics()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	if err := api.Route(mux, []string{"http-methods"}, nil, m.instrument); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"HEAD", "OPTIONS", "POST"} {
+		r, err := http.NewRequest(method, "/get", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	r, err := http.NewRequest("GET", "/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	tests := []string{
+		`httpbin_http_requests_total{route="/get",method="HEAD",code="200"} 1`,
+		`httpbin_http_requests_total{route="/get",method="OPTIONS",code="200"} 1`,
+		`httpbin_http_requests_total{route="/get",method="POST",code="405"} 1`,
+		`httpbin_http_response_bytes_total{route="/get"} 0`,
+	}
+	for _, tt := range tests {
+		if !strings.Contains(w.Body.String(), tt+"\n") {
+			t.Errorf("metrics endpoint doesn't contain %v", tt)
+		}
+	}
+}
+
+func TestMetricsMethods(t *testing.T) {
+	m := newMetrics()
+	tests := []struct {
+		method string
+		code   int
+		body   bool
+	}{
+		{"GET", http.StatusOK, true},
+		{"HEAD", http.StatusOK, false},
+		{"OPTIONS", http.StatusOK, false},
+		{"POST", http.StatusMethodNotAllowed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			r, err := http.NewRequest(tt.method, "/metrics", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, r)
+			if status := w.Code; status != tt.code {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.code)
+			}
+			if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+				t.Errorf("handler returned wrong Allow header: got %v want %v",
+					allow, "GET, HEAD, OPTIONS")
+			}
+			if body := w.Body.Len() > 0; body != tt.body {
+				t.Errorf("handler returned wrong body presence: got %v want %v", body, tt.body)
+			}
+		})
+	}
+}
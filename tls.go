@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"time"
+)
+
+const selfSignedValidity = 365 * 24 * time.Hour
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// generateSelfSignedCertificate creates a throwaway CA and a leaf certificate
+// signed by it for the given host names and IP addresses. The PEM encoded CA
+// certificate is returned so that clients can be told to trust it.
+func generateSelfSignedCertificate(hosts []string) (tls.Certificate, []byte, error) {
+	var certificate tls.Certificate
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(selfSignedValidity)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return certificate, nil, err
+	}
+	caSerial, err := newSerialNumber()
+	if err != nil {
+		return certificate, nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          caSerial,
+		Subject:               pkix.Name{Organization: []string{"httpbin-go"}, CommonName: "httpbin-go CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return certificate, nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return certificate, nil, err
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return certificate, nil, err
+	}
+	leafSerial, err := newSerialNumber()
+	if err != nil {
+		return certificate, nil, err
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: leafSerial,
+		Subject:      pkix.Name{Organization: []string{"httpbin-go"}, CommonName: "httpbin-go"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			leafTemplate.IPAddresses = append(leafTemplate.IPAddresses, ip)
+		} else {
+			leafTemplate.DNSNames = append(leafTemplate.DNSNames, host)
+		}
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		return certificate, nil, err
+	}
+
+	certificate.Certificate = [][]byte{leafDER, caDER}
+	certificate.PrivateKey = leafKey
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	return certificate, caPEM, nil
+}
+
+// loadCertificate returns the configured certificate, or a freshly generated
+// self-signed one when no certificate file is configured.
+func loadCertificate(cfg *tlsConfig) (tls.Certificate, error) {
+	if cfg.Cert != "" {
+		return tls.LoadX509KeyPair(cfg.Cert, cfg.Key)
+	}
+	certificate, caPEM, err := generateSelfSignedCertificate(cfg.Hosts)
+	if err != nil {
+		return certificate, err
+	}
+	if cfg.CAOutput != "" {
+		if err := ioutil.WriteFile(cfg.CAOutput, caPEM, 0644); err != nil {
+			return certificate, err
+		}
+	}
+	return certificate, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// newMTLSConfig asks clients for a certificate without verifying it during
+// the handshake, so that /client-cert can report verification errors.
+func newMTLSConfig(certificate tls.Certificate, cfg *mtlsConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequestClientCert,
+	}
+	if cfg.Require {
+		tlsConfig.ClientAuth = tls.RequireAnyClientCert
+	}
+	if cfg.ClientCA != "" {
+		pool, err := loadCertPool(cfg.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+	}
+	return tlsConfig, nil
+}